@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"reflect"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// LoadAs is the generic form of Load: it reads the configuration file at path
+// into a new value of T, which must be a struct type.
+//
+// Fields missing from the file get the value of their `default` tag,
+// so the same struct yields the same defaults with every loader.
+//
+// Example: cfg, err := conf.LoadAs[Config]("config.yaml")
+func LoadAs[T any](path string, opts ...option) (T, error) {
+	var cfg T
+	err := Load(path, &cfg, opts...)
+	return cfg, err
+}
+
+// ApplyDefaults sets every zero-valued field of a struct to the value
+// of its `default` tag, descending into nested structs.
+//
+// Defaults are parsed according to the field type: durations use
+// time.ParseDuration syntax ("5s"), slices are comma-separated ("a,b")
+// and maps are comma-separated key=value pairs ("a=1,b=2").
+//
+// Example: `mapstructure:"timeout" default:"5s"`
+func ApplyDefaults(cfgPtr interface{}) error {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return err
+	}
+	return applyDefaults(elem, func(string) bool { return false }, nil)
+}
+
+// applyDefaults sets the `default` tag value of zero-valued fields whose key
+// is not set by a higher layer, calling onApply with the key of each field set.
+func applyDefaults(elem reflect.Value, isSet func(key string) bool, onApply func(key string)) error {
+	return reflectx.ApplyDefaults(elem, isSet, onApply)
+}
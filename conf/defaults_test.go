@@ -0,0 +1,86 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultsDBConfig struct {
+	Host    string        `mapstructure:"host" default:"localhost"`
+	Port    int           `mapstructure:"port" default:"5432"`
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+type defaultsConfig struct {
+	Service string            `mapstructure:"service" default:"app"`
+	Tags    []string          `mapstructure:"tags" default:"a,b,c"`
+	Labels  map[string]string `mapstructure:"labels" default:"team=core,tier=backend"`
+	Ratio   float64           `mapstructure:"ratio" default:"0.5"`
+	DB      defaultsDBConfig  `mapstructure:"db"`
+}
+
+func TestLoadAs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults for missing keys", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "service: bot\ndb:\n  port: 6543\n")
+
+		cfg, err := LoadAs[defaultsConfig](path)
+		require.NoError(t, err)
+
+		assert.Equal(t, defaultsConfig{
+			Service: "bot",
+			Tags:    []string{"a", "b", "c"},
+			Labels:  map[string]string{"team": "core", "tier": "backend"},
+			Ratio:   0.5,
+			DB:      defaultsDBConfig{Host: "localhost", Port: 6543, Timeout: 5 * time.Second},
+		}, cfg)
+	})
+
+	t.Run("file values replace default slices and maps", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "tags: [x]\nlabels:\n  team: edge\n")
+
+		cfg, err := LoadAs[defaultsConfig](path)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"x"}, cfg.Tags)
+		assert.Equal(t, map[string]string{"team": "edge"}, cfg.Labels)
+	})
+
+	t.Run("explicit zero in file wins over default", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "db:\n  port: 0\n")
+
+		cfg, err := LoadAs[defaultsConfig](path)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cfg.DB.Port)
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		t.Parallel()
+		type badConfig struct {
+			Timeout time.Duration `mapstructure:"timeout" default:"5 seconds"`
+		}
+		path := createTestYAML(t, "other: 1\n")
+
+		_, err := LoadAs[badConfig](path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid default value for field timeout")
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultsConfig{Service: "preset"}
+	require.NoError(t, ApplyDefaults(&cfg))
+
+	assert.Equal(t, "preset", cfg.Service)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+}
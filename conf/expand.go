@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envVarPattern matches ${VAR} and ${VAR:-default}. Secret references such as
+// ${ENV:NAME} do not match and are left to EnvResolver.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// UnsetVariablesError lists the environment variables referenced by a
+// configuration file that are not set and have no default.
+// It is returned when loading with WithEnvExpansion.
+type UnsetVariablesError struct {
+	Names []string // Names of the unset variables (e.g. "LOG_HOST")
+}
+
+func (e *UnsetVariablesError) Error() string {
+	return "unset environment variables: " + strings.Join(e.Names, ", ")
+}
+
+// WithEnvExpansion expands ${VAR} and ${VAR:-default} references in the string
+// values of the configuration file, after it has been parsed:
+//
+//	udp_address: ${LOG_HOST}:5140
+//	level: ${LOG_LEVEL:-info}
+//
+// As in the shell, the default is used when the variable is unset or empty.
+// Loading fails with an *ops.Error of kind ops.KindInvalid wrapping an
+// *UnsetVariablesError if any referenced variable is unset and has no default.
+func WithEnvExpansion() option {
+	return func(o *options) {
+		o.expandEnv = true
+	}
+}
+
+// expandEnv replaces variable references in every string value of v.
+func expandEnv(v *viper.Viper) error {
+	unset := map[string]bool{}
+	for _, key := range v.AllKeys() {
+		value, changed := expandValue(v.Get(key), unset)
+		if changed {
+			v.Set(key, value)
+		}
+	}
+	if len(unset) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(unset))
+	for name := range unset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &UnsetVariablesError{Names: names}
+}
+
+// expandValue expands strings, including those nested in lists and maps,
+// and reports whether anything was replaced.
+func expandValue(value interface{}, unset map[string]bool) (interface{}, bool) {
+	switch val := value.(type) {
+	case string:
+		expanded := expandString(val, unset)
+		return expanded, expanded != val
+	case []interface{}:
+		changed := false
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			var c bool
+			out[i], c = expandValue(item, unset)
+			changed = changed || c
+		}
+		return out, changed
+	case map[string]interface{}:
+		changed := false
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			var c bool
+			out[k], c = expandValue(item, unset)
+			changed = changed || c
+		}
+		return out, changed
+	default:
+		return value, false
+	}
+}
+
+func expandString(s string, unset map[string]bool) string {
+	return envVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envVarPattern.FindStringSubmatch(ref)
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+
+		value, ok := os.LookupEnv(name)
+		switch {
+		case ok && value != "":
+			return value
+		case hasDefault:
+			return def
+		case !ok:
+			unset[name] = true
+		}
+		return value
+	})
+}
@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shanth1/gotools/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expandConfig struct {
+	Address string            `mapstructure:"udp_address"`
+	Level   string            `mapstructure:"level"`
+	Token   string            `mapstructure:"token"`
+	Hosts   []string          `mapstructure:"hosts"`
+	Labels  map[string]string `mapstructure:"labels"`
+	Port    int               `mapstructure:"port"`
+}
+
+func TestLoad_EnvExpansion(t *testing.T) {
+	const content = `
+udp_address: ${TEST_EXPAND_HOST}:5140
+level: ${TEST_EXPAND_LEVEL:-info}
+token: ${ENV:TEST_EXPAND_TOKEN}
+hosts: ["${TEST_EXPAND_HOST}", "static"]
+labels:
+  region: ${TEST_EXPAND_REGION:-eu}
+port: ${TEST_EXPAND_PORT:-8080}
+`
+
+	t.Run("expands variables and defaults", func(t *testing.T) {
+		t.Setenv("TEST_EXPAND_HOST", "logs.local")
+		t.Setenv("TEST_EXPAND_LEVEL", "")
+		t.Setenv("TEST_EXPAND_TOKEN", "secret")
+		t.Setenv("TEST_EXPAND_PORT", "9000")
+		path := createTestYAML(t, content)
+
+		var cfg expandConfig
+		require.NoError(t, Load(path, &cfg, WithEnvExpansion(), WithSecrets()))
+
+		assert.Equal(t, "logs.local:5140", cfg.Address)
+		assert.Equal(t, "info", cfg.Level, "default should be used for empty variables")
+		assert.Equal(t, "secret", cfg.Token, "secret references should be resolved, not expanded")
+		assert.Equal(t, []string{"logs.local", "static"}, cfg.Hosts)
+		assert.Equal(t, map[string]string{"region": "eu"}, cfg.Labels)
+		assert.Equal(t, 9000, cfg.Port)
+	})
+
+	t.Run("reports every unset variable", func(t *testing.T) {
+		t.Setenv("TEST_EXPAND_TOKEN", "secret")
+		path := createTestYAML(t, "udp_address: ${TEST_EXPAND_HOST}:${TEST_EXPAND_UDP_PORT}\nlevel: ${TEST_EXPAND_HOST}\n")
+
+		var cfg expandConfig
+		err := Load(path, &cfg, WithEnvExpansion())
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ops.KindInvalid))
+
+		var unset *UnsetVariablesError
+		require.True(t, errors.As(err, &unset))
+		assert.Equal(t, []string{"TEST_EXPAND_HOST", "TEST_EXPAND_UDP_PORT"}, unset.Names)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		path := createTestYAML(t, "udp_address: ${TEST_EXPAND_HOST}:5140\n")
+
+		var cfg expandConfig
+		require.NoError(t, Load(path, &cfg))
+		assert.Equal(t, "${TEST_EXPAND_HOST}:5140", cfg.Address)
+	})
+}
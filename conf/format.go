@@ -0,0 +1,44 @@
+package conf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the encoding of a configuration document.
+type Format string
+
+const (
+	FormatYAML   Format = "yaml"   // YAML document (.yaml, .yml)
+	FormatJSON   Format = "json"   // JSON document (.json)
+	FormatTOML   Format = "toml"   // TOML document (.toml)
+	FormatDotenv Format = "dotenv" // Flat KEY=VALUE document (.env)
+)
+
+var extToFormat = map[string]Format{
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".json": FormatJSON,
+	".toml": FormatTOML,
+	".env":  FormatDotenv,
+}
+
+// FormatFromPath detects the configuration format from the file extension.
+// It returns an error if the extension is not supported.
+func FormatFromPath(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if f, ok := extToFormat[ext]; ok {
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported config format for file %q: use one of .yaml, .yml, .json, .toml, .env or WithFormat", path)
+}
+
+func (f Format) validate() error {
+	switch f {
+	case FormatYAML, FormatJSON, FormatTOML, FormatDotenv:
+		return nil
+	default:
+		return fmt.Errorf("unsupported config format %q", string(f))
+	}
+}
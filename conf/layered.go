@@ -0,0 +1,152 @@
+package conf
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+	"github.com/spf13/viper"
+)
+
+// Origin identifies the configuration layer that supplied a field's value.
+type Origin string
+
+const (
+	OriginUnset   Origin = "unset"   // No layer supplied a value, the field keeps its zero value
+	OriginDefault Origin = "default" // Value taken from the `default` tag
+	OriginFile    Origin = "file"    // Value read from the configuration file
+	OriginEnv     Origin = "env"     // Value read from the environment variable named by the `env` tag
+	OriginFlag    Origin = "flag"    // Value read from the command-line flag named by the `flag` tag
+)
+
+// Report maps the dotted key of every config field (e.g. "log.level")
+// to the layer its final value came from.
+type Report map[string]Origin
+
+// LoadLayered fills a struct from several layers, each one overriding the previous:
+//
+//  1. `default` tags, for fields the file does not set;
+//  2. the configuration file at path, decoded through `mapstructure` tags;
+//  3. environment variables named by `env` tags;
+//  4. command-line flags named by `flag` tags, parsed from args.
+//
+// An empty path skips the file and nil args skip the flags.
+// Options are the same as for Load.
+// With WithSecrets, secret references in the merged struct are resolved.
+// The struct is then checked with Validate.
+// The returned Report tells which layer won for each field.
+//
+// Example: `mapstructure:"port" env:"APP_PORT" flag:"port" default:"8080"`
+func LoadLayered(path string, args []string, cfgPtr interface{}, opts ...option) (Report, error) {
+	val := reflect.ValueOf(cfgPtr)
+
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
+	}
+	elem := val.Elem()
+
+	o := newOptions(opts...)
+	v := viper.New()
+	if path != "" {
+		var err error
+		if v, err = readFile(path, o); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := o.decode(v, elem)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := overlayEnv(elem, report); err != nil {
+		return nil, err
+	}
+
+	if args != nil {
+		if err := overlayFlags(elem, args, report); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := o.finish(cfgPtr, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// overlayEnv sets every field with an `env` tag whose variable is present.
+// The tag may list several names separated by commas, each prefixed by the
+// `env-prefix` tags of the enclosing structs; the first one set wins.
+func overlayEnv(elem reflect.Value, report Report) error {
+	return reflectx.Walk(elem, func(f reflectx.Field) error {
+		for _, name := range f.EnvNames() {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			if err := reflectx.SetString(f.Value, value); err != nil {
+				return fmt.Errorf("invalid value in environment variable %s: %w", name, err)
+			}
+			report[f.Key()] = OriginEnv
+			return nil
+		}
+		return nil
+	})
+}
+
+// overlayFlags parses args against the fields with a `flag` tag, named as in
+// the flags package: `flag:"db"` on a struct and `flag:"host"` on its field give -db.host.
+// Only flags present in args change the struct, so lower layers are kept otherwise.
+func overlayFlags(elem reflect.Value, args []string, report Report) error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	keys := map[string]string{}
+
+	err := reflectx.Walk(elem, func(f reflectx.Field) error {
+		name := f.FlagName()
+		if name == "" {
+			return nil
+		}
+		if key, ok := keys[name]; ok {
+			return fmt.Errorf("flag -%s is defined by both %s and %s", name, key, f.Key())
+		}
+		fs.Var(fieldValue{f.Value}, name, f.Tag("usage"))
+		keys[name] = f.Key()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		report[keys[fl.Name]] = OriginFlag
+	})
+
+	return nil
+}
+
+// fieldValue adapts a struct field to the flag.Value interface.
+type fieldValue struct {
+	v reflect.Value
+}
+
+func (f fieldValue) String() string {
+	if !f.v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(f.v.Interface())
+}
+
+func (f fieldValue) Set(s string) error {
+	return reflectx.SetString(f.v, s)
+}
+
+func (f fieldValue) IsBoolFlag() bool {
+	return f.v.IsValid() && f.v.Kind() == reflect.Bool
+}
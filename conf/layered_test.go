@@ -0,0 +1,117 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type layeredLogConfig struct {
+	Level   string `mapstructure:"level" env:"TEST_LAYERED_LOG_LEVEL" flag:"log-level" default:"info"`
+	Console bool   `mapstructure:"console" flag:"console"`
+}
+
+type layeredConfig struct {
+	Service string           `mapstructure:"service" env:"TEST_LAYERED_SERVICE" default:"app"`
+	Port    int              `mapstructure:"port" env:"TEST_LAYERED_PORT" flag:"port" default:"8080"`
+	Timeout time.Duration    `mapstructure:"timeout" default:"5s"`
+	Log     layeredLogConfig `mapstructure:"log"`
+}
+
+func TestLoadLayered(t *testing.T) {
+	t.Run("precedence of all layers", func(t *testing.T) {
+		path := createTestYAML(t, "service: from-file\nport: 9000\nlog:\n  level: warn\n")
+		t.Setenv("TEST_LAYERED_PORT", "9100")
+		t.Setenv("TEST_LAYERED_LOG_LEVEL", "error")
+
+		var cfg layeredConfig
+		report, err := LoadLayered(path, []string{"-log-level", "debug", "-console"}, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "from-file", cfg.Service)
+		assert.Equal(t, 9100, cfg.Port)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.True(t, cfg.Log.Console)
+
+		assert.Equal(t, Report{
+			"service":     OriginFile,
+			"port":        OriginEnv,
+			"timeout":     OriginDefault,
+			"log.level":   OriginFlag,
+			"log.console": OriginFlag,
+		}, report)
+	})
+
+	t.Run("defaults only", func(t *testing.T) {
+		var cfg layeredConfig
+		report, err := LoadLayered("", nil, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "app", cfg.Service)
+		assert.Equal(t, 8080, cfg.Port)
+		assert.Equal(t, "info", cfg.Log.Level)
+		assert.Equal(t, OriginDefault, report["port"])
+		assert.Equal(t, OriginUnset, report["log.console"])
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		t.Setenv("TEST_LAYERED_PORT", "not-a-number")
+
+		var cfg layeredConfig
+		_, err := LoadLayered("", nil, &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid value in environment variable TEST_LAYERED_PORT")
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		var cfg layeredConfig
+		_, err := LoadLayered("", []string{"-unknown"}, &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error parsing flags")
+	})
+
+	t.Run("nested names are prefixed", func(t *testing.T) {
+		type endpoint struct {
+			Host string `mapstructure:"host" env:"HOST" flag:"host"`
+		}
+		type config struct {
+			DB    endpoint `mapstructure:"db" env-prefix:"TEST_LAYERED_DB_" flag:"db"`
+			Cache endpoint `mapstructure:"cache" env-prefix:"TEST_LAYERED_CACHE_" flag:"cache"`
+		}
+		t.Setenv("TEST_LAYERED_DB_HOST", "db.local")
+
+		var cfg config
+		report, err := LoadLayered("", []string{"-cache.host", "cache.local"}, &cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "db.local", cfg.DB.Host)
+		assert.Equal(t, "cache.local", cfg.Cache.Host)
+		assert.Equal(t, OriginEnv, report["db.host"])
+		assert.Equal(t, OriginFlag, report["cache.host"])
+	})
+
+	t.Run("duplicate flag", func(t *testing.T) {
+		type config struct {
+			Host  string `mapstructure:"host" flag:"host"`
+			Other string `mapstructure:"other" flag:"host"`
+		}
+		var cfg config
+		_, err := LoadLayered("", []string{}, &cfg)
+		assert.EqualError(t, err, "flag -host is defined by both host and other")
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		var cfg layeredConfig
+		_, err := LoadLayered("non-existent-file.yaml", nil, &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading config file")
+	})
+
+	t.Run("target is not a pointer to struct", func(t *testing.T) {
+		var i int
+		_, err := LoadLayered("", nil, &i)
+		assert.EqualError(t, err, "expected a pointer to a struct, but got *int")
+	})
+}
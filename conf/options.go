@@ -0,0 +1,118 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/shanth1/gotools/consts"
+	"github.com/shanth1/gotools/internal/reflectx"
+	"github.com/shanth1/gotools/ops"
+	"github.com/spf13/viper"
+)
+
+type options struct {
+	format    Format
+	secrets   bool
+	resolvers []Resolver
+	profile   consts.Env
+	strict    bool
+	expandEnv bool
+	onUnset   func(keys []string)
+}
+
+// option defines a function for configuring how configuration is loaded.
+type option func(*options)
+
+func newOptions(opts ...option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithFormat sets the format of the configuration file explicitly
+// instead of detecting it from the file extension.
+func WithFormat(format Format) option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithSecrets resolves secret references (see ResolveSecrets) in the loaded struct.
+// The given resolvers are tried before the default file:// and ${ENV:NAME} resolvers.
+// Without this option, values such as "file:///data/app.db" are kept as they are.
+func WithSecrets(resolvers ...Resolver) option {
+	return func(o *options) {
+		o.secrets = true
+		o.resolvers = append(o.resolvers, resolvers...)
+	}
+}
+
+// WithStrict makes loading fail if the file contains keys that do not match
+// any field of the config struct. The error is an *ops.Error of kind
+// ops.KindInvalid wrapping an *UnknownKeysError with the dotted path of every unknown key.
+func WithStrict() option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// decode fills the struct behind elem from `default` tags and the viper settings,
+// and reports which of the two supplied each field.
+func (o *options) decode(v *viper.Viper, elem reflect.Value) (Report, error) {
+	const op = "conf.Load"
+
+	if o.expandEnv {
+		if err := expandEnv(v); err != nil {
+			return nil, ops.Wrap(op, ops.KindInvalid, err)
+		}
+	}
+
+	if o.strict {
+		if unknown := unknownKeys(v.AllSettings(), elem.Type()); len(unknown) > 0 {
+			return nil, ops.Wrap(op, ops.KindInvalid, &UnknownKeysError{Keys: unknown})
+		}
+	}
+
+	report := Report{}
+	_ = reflectx.Walk(elem, func(f reflectx.Field) error {
+		report[f.Key()] = OriginUnset
+		return nil
+	})
+
+	err := applyDefaults(elem, v.IsSet, func(key string) {
+		report[key] = OriginDefault
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := v.Unmarshal(elem.Addr().Interface()); err != nil {
+		return nil, fmt.Errorf("error unmarshalling config: %w", err)
+	}
+	for key := range report {
+		if v.IsSet(key) {
+			report[key] = OriginFile
+		}
+	}
+
+	return report, nil
+}
+
+// finish reports unset fields, resolves secret references in the loaded struct
+// if enabled with WithSecrets, and validates it.
+// Secrets are resolved after decoding so that they never appear in decoding errors.
+func (o *options) finish(cfgPtr interface{}, report Report) error {
+	if o.onUnset != nil {
+		if keys := report.unsetKeys(); len(keys) > 0 {
+			o.onUnset(keys)
+		}
+	}
+	if o.secrets {
+		if err := ResolveSecrets(cfgPtr, append(append([]Resolver{}, o.resolvers...), DefaultResolvers()...)...); err != nil {
+			return err
+		}
+	}
+	return Validate(cfgPtr)
+}
@@ -0,0 +1,69 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shanth1/gotools/consts"
+)
+
+// ProfileEnvVar is the environment variable LoadProfile reads
+// when no profile is set with WithProfile.
+const ProfileEnvVar = "APP_ENV"
+
+// WithProfile merges the overlay file of the given environment over the base file.
+// For "config.yaml" and consts.EnvProd the overlay is "config.prod.yaml".
+// Maps are merged deeply, scalars and lists are replaced by the overlay.
+// A missing overlay file is not an error: the base file is used as is.
+func WithProfile(env consts.Env) option {
+	return func(o *options) {
+		o.profile = env
+	}
+}
+
+// LoadProfile loads the base configuration file at path merged with the overlay
+// of the selected profile, and returns that profile.
+//
+// The profile is taken from WithProfile or, if not given, from the APP_ENV
+// environment variable. An empty profile loads the base file only.
+//
+// Example: env, err := conf.LoadProfile("config.yaml", &cfg) // APP_ENV=prod reads config.prod.yaml
+func LoadProfile(path string, cfgPtr interface{}, opts ...option) (consts.Env, error) {
+	o := newOptions(opts...)
+	if o.profile == "" {
+		o.profile = consts.Env(os.Getenv(ProfileEnvVar))
+	}
+
+	if err := load(path, cfgPtr, o); err != nil {
+		return "", err
+	}
+	return o.profile, nil
+}
+
+// ProfilePath returns the overlay file path for env next to the base file,
+// inserting the environment name before the extension.
+func ProfilePath(path string, env consts.Env) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + string(env) + ext
+}
+
+// overlayPath returns the overlay file to merge for the configured profile,
+// or an empty string if there is no profile or no overlay file.
+func (o *options) overlayPath(path string) (string, error) {
+	if o.profile == "" {
+		return "", nil
+	}
+
+	overlay := ProfilePath(path, o.profile)
+	if _, err := os.Stat(overlay); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error reading config file %q: %w", overlay, err)
+	}
+	return overlay, nil
+}
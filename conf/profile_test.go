@@ -0,0 +1,100 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shanth1/gotools/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profileConfig struct {
+	Service string `mapstructure:"service"`
+	Log     struct {
+		Level   string `mapstructure:"level"`
+		Console bool   `mapstructure:"console"`
+	} `mapstructure:"log"`
+	Hosts []string `mapstructure:"hosts"`
+}
+
+func createProfileFiles(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	base := "service: bot\nlog:\n  level: debug\n  console: true\nhosts: [a, b]\n"
+	prod := "log:\n  level: warn\nhosts: [c]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(base), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.prod.yaml"), []byte(prod), 0644))
+	return filepath.Join(dir, "config.yaml")
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Run("explicit profile merges overlay", func(t *testing.T) {
+		path := createProfileFiles(t)
+
+		var cfg profileConfig
+		env, err := LoadProfile(path, &cfg, WithProfile(consts.EnvProd))
+
+		require.NoError(t, err)
+		assert.Equal(t, consts.EnvProd, env)
+		assert.Equal(t, "bot", cfg.Service)
+		assert.Equal(t, "warn", cfg.Log.Level)
+		assert.True(t, cfg.Log.Console, "maps are merged deeply")
+		assert.Equal(t, []string{"c"}, cfg.Hosts, "lists are replaced")
+	})
+
+	t.Run("profile from APP_ENV", func(t *testing.T) {
+		path := createProfileFiles(t)
+		t.Setenv(ProfileEnvVar, string(consts.EnvProd))
+
+		var cfg profileConfig
+		env, err := LoadProfile(path, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, consts.EnvProd, env)
+		assert.Equal(t, "warn", cfg.Log.Level)
+	})
+
+	t.Run("missing overlay uses base file", func(t *testing.T) {
+		path := createProfileFiles(t)
+
+		var cfg profileConfig
+		env, err := LoadProfile(path, &cfg, WithProfile(consts.EnvStage))
+
+		require.NoError(t, err)
+		assert.Equal(t, consts.EnvStage, env)
+		assert.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("no profile", func(t *testing.T) {
+		path := createProfileFiles(t)
+		t.Setenv(ProfileEnvVar, "")
+
+		var cfg profileConfig
+		env, err := LoadProfile(path, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, consts.Env(""), env)
+		assert.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("broken overlay", func(t *testing.T) {
+		path := createProfileFiles(t)
+		overlay := ProfilePath(path, consts.EnvDev)
+		require.NoError(t, os.WriteFile(overlay, []byte("log: [\n"), 0644))
+
+		var cfg profileConfig
+		_, err := LoadProfile(path, &cfg, WithProfile(consts.EnvDev))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading config file")
+		assert.Contains(t, err.Error(), "config.dev.yaml")
+	})
+}
+
+func TestProfilePath(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "conf/config.prod.yaml", ProfilePath("conf/config.yaml", consts.EnvProd))
+	assert.Equal(t, "settings.local.json", ProfilePath("settings.json", consts.EnvLocal))
+}
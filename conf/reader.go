@@ -7,25 +7,55 @@ import (
 	"github.com/spf13/viper"
 )
 
-// Load reads a YAML configuration file from the given path into a struct.
+// Load reads a configuration file from the given path into a struct.
 // The cfgPtr argument must be a pointer to the struct that will hold the configuration.
-func Load(path string, cfgPtr interface{}) error {
+//
+// The format is detected from the file extension (.yaml, .yml, .json, .toml, .env)
+// unless it is set explicitly with WithFormat. Files with another extension,
+// or none, are read as YAML.
+// Zero-valued fields missing from the file get the value of their `default` tag.
+// With WithSecrets, secret references (see ResolveSecrets) are resolved.
+// The loaded struct is then checked with Validate.
+func Load(path string, cfgPtr interface{}, opts ...option) error {
+	return load(path, cfgPtr, newOptions(opts...))
+}
+
+func load(path string, cfgPtr interface{}, o *options) error {
 	val := reflect.ValueOf(cfgPtr)
 
 	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
 	}
 
+	v, err := readFile(path, o)
+	if err != nil {
+		return err
+	}
+	report, err := o.decode(v, val.Elem())
+	if err != nil {
+		return err
+	}
+
+	return o.finish(cfgPtr, report)
+}
+
+// readFile reads the configuration file at path into a new viper instance,
+// merging the overlay of the selected profile if there is one.
+func readFile(path string, o *options) (*viper.Viper, error) {
 	v := viper.New()
-	v.SetConfigFile(path)
-	v.SetConfigType("yaml")
+	if err := mergeSource(v, File(path), o); err != nil {
+		return nil, err
+	}
 
-	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("error reading config file: %w", err)
+	overlay, err := o.overlayPath(path)
+	if err != nil {
+		return nil, err
 	}
-	if err := v.Unmarshal(cfgPtr); err != nil {
-		return fmt.Errorf("error unmarshalling config: %w", err)
+	if overlay != "" {
+		if err := mergeSource(v, File(overlay), o); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	return v, nil
 }
@@ -15,10 +15,21 @@ type TestConfig struct {
 	Enabled bool   `yaml:"enabled"`
 }
 
+type TestFormatConfig struct {
+	Service string `mapstructure:"service"`
+	Port    int    `mapstructure:"port"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
 func createTestYAML(t *testing.T, content string) string {
+	t.Helper()
+	return createTestFile(t, "config.yaml", content)
+}
+
+func createTestFile(t *testing.T, name, content string) string {
 	t.Helper()
 	dir := t.TempDir()
-	path := filepath.Join(dir, "config.yaml")
+	path := filepath.Join(dir, name)
 	err := os.WriteFile(path, []byte(content), 0644)
 	require.NoError(t, err)
 	return path
@@ -80,3 +91,111 @@ enabled: true
 		assert.EqualError(t, err, "expected a pointer to a struct, but got *int")
 	})
 }
+
+func TestLoad_Formats(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "config.yaml", "service: my-app\nport: 8080\nenabled: true\n"},
+		{"yml", "config.yml", "service: my-app\nport: 8080\nenabled: true\n"},
+		{"json", "config.json", `{"service": "my-app", "port": 8080, "enabled": true}`},
+		{"toml", "config.toml", "service = \"my-app\"\nport = 8080\nenabled = true\n"},
+		{"dotenv", "config.env", "SERVICE=my-app\nPORT=8080\nENABLED=true\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			path := createTestFile(t, tc.file, tc.content)
+
+			var cfg TestFormatConfig
+			err := Load(path, &cfg)
+
+			require.NoError(t, err)
+			assert.Equal(t, TestFormatConfig{Service: "my-app", Port: 8080, Enabled: true}, cfg)
+		})
+	}
+
+	t.Run("explicit format", func(t *testing.T) {
+		t.Parallel()
+		path := createTestFile(t, "config.conf", `{"service": "my-app"}`)
+
+		var cfg TestFormatConfig
+		err := Load(path, &cfg, WithFormat(FormatJSON))
+
+		require.NoError(t, err)
+		assert.Equal(t, "my-app", cfg.Service)
+	})
+
+	t.Run("unknown extension falls back to yaml", func(t *testing.T) {
+		t.Parallel()
+		for _, name := range []string{"app.cfg", "config"} {
+			path := createTestFile(t, name, "service: my-app")
+
+			var cfg TestFormatConfig
+			require.NoError(t, Load(path, &cfg), name)
+			assert.Equal(t, "my-app", cfg.Service, name)
+		}
+	})
+
+	t.Run("unsupported explicit format", func(t *testing.T) {
+		t.Parallel()
+		path := createTestFile(t, "config.yaml", "service: my-app")
+
+		var cfg TestFormatConfig
+		err := Load(path, &cfg, WithFormat("xml"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported config format "xml"`)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		t.Parallel()
+		path := createTestFile(t, "config.json", `{"service": `)
+
+		var cfg TestFormatConfig
+		err := Load(path, &cfg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading config file")
+		assert.Contains(t, err.Error(), "as json")
+	})
+}
+
+func TestLoad_LogComponents(t *testing.T) {
+	t.Parallel()
+
+	// Mirrors log.Config.Components: list elements are decoded through
+	// mapstructure and their names keep their dots and case.
+	type componentLevel struct {
+		Name  string `mapstructure:"name"`
+		Level string `mapstructure:"level"`
+	}
+	type Config struct {
+		Log struct {
+			Level      string           `mapstructure:"level"`
+			Components []componentLevel `mapstructure:"components"`
+		} `mapstructure:"log"`
+	}
+	path := createTestYAML(t, `
+log:
+  level: warn
+  components:
+    - name: db.*
+      level: debug
+    - name: HTTP.Server
+      level: info
+`)
+
+	var cfg Config
+	require.NoError(t, Load(path, &cfg))
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, []componentLevel{
+		{Name: "db.*", Level: "debug"},
+		{Name: "HTTP.Server", Level: "info"},
+	}, cfg.Log.Components)
+}
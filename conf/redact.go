@@ -0,0 +1,179 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// Mask replaces the value of secret fields in Redact and Dump output.
+const Mask = "******"
+
+// secretNameParts are matched against field names and keys to detect secrets
+// that are not tagged explicitly.
+var secretNameParts = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "private_key", "credential"}
+
+// Redact returns the values of a config struct (or pointer to one) as a nested
+// map keyed by `mapstructure` names, with secret fields replaced by Mask.
+// It returns nil if cfg is not a struct.
+//
+// A field is secret if it is tagged `secret:"true"` or if its name contains
+// password, passwd, secret, token, apikey, api_key, private_key or credential.
+// Use `secret:"false"` to opt out of the name-based detection.
+// Empty secret fields are kept empty, so missing secrets remain visible.
+//
+// Structs behind pointers, and structs held in slices, arrays and maps, are
+// redacted too: they appear as nested maps (nil for nil pointers).
+func Redact(cfg interface{}) map[string]interface{} {
+	elem, ok := structValue(cfg)
+	if !ok {
+		return nil
+	}
+	return redactStruct(elem)
+}
+
+// Dump returns the values of a config struct as a flat map with dotted keys
+// (e.g. "email.password"), masked like Redact, ready for startup logging:
+//
+//	logger.Info().Any("config", conf.Dump(cfg)).Msg("effective configuration")
+//
+// Nested structs, including those behind pointers and in maps, are flattened;
+// slices of structs are kept as lists of redacted maps.
+func Dump(cfg interface{}) map[string]interface{} {
+	nested := Redact(cfg)
+	if nested == nil {
+		return nil
+	}
+	out := map[string]interface{}{}
+	flatten(out, "", nested)
+	return out
+}
+
+func flatten(out map[string]interface{}, prefix string, m map[string]interface{}) {
+	for key, value := range m {
+		if child, ok := value.(map[string]interface{}); ok {
+			flatten(out, prefix+key+".", child)
+			continue
+		}
+		out[prefix+key] = value
+	}
+}
+
+func structValue(cfg interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	// Work on an addressable copy so that fields can be read uniformly.
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	return cp, true
+}
+
+func redactStruct(elem reflect.Value) map[string]interface{} {
+	out := map[string]interface{}{}
+	_ = reflectx.Walk(elem, func(f reflectx.Field) error {
+		m := out
+		for _, key := range f.Keys[:len(f.Keys)-1] {
+			child, ok := m[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[key] = child
+			}
+			m = child
+		}
+		m[f.Keys[len(f.Keys)-1]] = redactedField(f)
+		return nil
+	})
+	return out
+}
+
+func redactedField(f reflectx.Field) interface{} {
+	if isSecret(f) && !f.Value.IsZero() {
+		return Mask
+	}
+	return redactedValue(f.Value)
+}
+
+// redactedValue returns v as plain data, redacting the structs it contains.
+func redactedValue(v reflect.Value) interface{} {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if !containsStruct(v.Type()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactedValue(v.Elem())
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		return redactStruct(cp)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactedValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = redactedValue(iter.Value())
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// containsStruct reports whether values of type t hold config structs to redact,
+// directly or through pointers, slices, arrays and maps.
+func containsStruct(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return !reflectx.IsLeaf(t)
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsStruct(t.Elem())
+	case reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+func isSecret(f reflectx.Field) bool {
+	if tag, ok := f.StructField.Tag.Lookup("secret"); ok {
+		return tag == "true"
+	}
+
+	names := []string{strings.ToLower(f.StructField.Name), f.Keys[len(f.Keys)-1]}
+	for _, name := range names {
+		for _, part := range secretNameParts {
+			if strings.Contains(name, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package conf
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shanth1/gotools/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+type redactConfig struct {
+	Service  string             `mapstructure:"service"`
+	Timeout  time.Duration      `mapstructure:"timeout"`
+	APIKey   string             `mapstructure:"api_key"`
+	Internal string             `mapstructure:"internal" secret:"true"`
+	TokenTTL time.Duration      `mapstructure:"token_ttl" secret:"false"`
+	Email    notify.EmailConfig `mapstructure:"email"`
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	cfg := redactConfig{
+		Service:  "bot",
+		Timeout:  5 * time.Second,
+		APIKey:   "key-123",
+		Internal: "hidden",
+		TokenTTL: time.Hour,
+		Email:    notify.EmailConfig{Host: "smtp.example.com", Port: 587, Username: "user", Password: "p4ss"},
+	}
+
+	t.Run("nested map", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, map[string]interface{}{
+			"service":   "bot",
+			"timeout":   "5s",
+			"api_key":   Mask,
+			"internal":  Mask,
+			"token_ttl": "1h0m0s",
+			"email": map[string]interface{}{
+				"host":     "smtp.example.com",
+				"port":     587,
+				"username": "user",
+				"password": Mask,
+				"from":     "",
+			},
+		}, Redact(&cfg))
+	})
+
+	t.Run("empty secrets stay empty", func(t *testing.T) {
+		t.Parallel()
+		out := Redact(redactConfig{})
+		assert.Equal(t, "", out["api_key"])
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, Redact(42))
+		assert.Nil(t, Dump((*redactConfig)(nil)))
+	})
+
+	t.Run("flat dump", func(t *testing.T) {
+		t.Parallel()
+		out := Dump(cfg)
+		assert.Equal(t, "smtp.example.com", out["email.host"])
+		assert.Equal(t, Mask, out["email.password"])
+		assert.Equal(t, Mask, out["api_key"])
+		assert.NotContains(t, fmt.Sprint(out), "p4ss")
+		assert.NotContains(t, fmt.Sprint(out), "key-123")
+	})
+}
+
+type nestedSecretsConfig struct {
+	Primary  *notify.EmailConfig           `mapstructure:"primary"`
+	Missing  *notify.EmailConfig           `mapstructure:"missing"`
+	Fallback []notify.EmailConfig          `mapstructure:"fallback"`
+	Backends []*notify.EmailConfig         `mapstructure:"backends"`
+	Named    map[string]notify.EmailConfig `mapstructure:"named"`
+	Hosts    []string                      `mapstructure:"hosts"`
+}
+
+func TestRedact_NestedSecrets(t *testing.T) {
+	t.Parallel()
+
+	cfg := nestedSecretsConfig{
+		Primary:  &notify.EmailConfig{Host: "smtp.example.com", Password: "p0inter"},
+		Fallback: []notify.EmailConfig{{Host: "backup.example.com", Password: "l1st"}},
+		Backends: []*notify.EmailConfig{{Password: "l1st-ptr"}, nil},
+		Named:    map[string]notify.EmailConfig{"alerts": {Password: "m4p"}},
+		Hosts:    []string{"a", "b"},
+	}
+
+	t.Run("pointer to struct", func(t *testing.T) {
+		t.Parallel()
+		out := Redact(cfg)
+		primary, ok := out["primary"].(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "smtp.example.com", primary["host"])
+		assert.Equal(t, Mask, primary["password"])
+		assert.Nil(t, out["missing"])
+	})
+
+	t.Run("structs in slices and maps", func(t *testing.T) {
+		t.Parallel()
+		out := Redact(cfg)
+		fallback, ok := out["fallback"].([]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, Mask, fallback[0].(map[string]interface{})["password"])
+		assert.Equal(t, "backup.example.com", fallback[0].(map[string]interface{})["host"])
+
+		backends, ok := out["backends"].([]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, Mask, backends[0].(map[string]interface{})["password"])
+		assert.Nil(t, backends[1])
+
+		named, ok := out["named"].(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, Mask, named["alerts"].(map[string]interface{})["password"])
+
+		assert.Equal(t, []string{"a", "b"}, out["hosts"])
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		t.Parallel()
+		out := Dump(&cfg)
+		assert.Equal(t, Mask, out["primary.password"])
+		assert.Equal(t, Mask, out["named.alerts.password"])
+		for _, secret := range []string{"p0inter", "l1st", "l1st-ptr", "m4p"} {
+			assert.NotContains(t, fmt.Sprint(out), secret)
+		}
+	})
+}
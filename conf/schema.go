@@ -0,0 +1,240 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// SchemaDraft is the JSON Schema dialect produced by Schema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches time.ParseDuration syntax such as "1h30m" or "250ms".
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema generates a JSON Schema document describing a config struct
+// (or pointer to one), so that editors can validate configuration files.
+//
+// Property names follow the keys Load accepts (`mapstructure` tags, or the
+// lower-cased field name). Field types map to JSON types, `usage` tags become
+// descriptions, `default` tags become defaults and `validate` rules become
+// required, minimum/maximum, minLength/maxLength and enum constraints.
+// Objects reject unknown properties, matching Load with WithStrict.
+func Schema(cfg interface{}) ([]byte, error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct or a pointer to a struct, but got %T", cfg)
+	}
+
+	root, err := objectSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	root["$schema"] = SchemaDraft
+	root["title"] = t.Name()
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func objectSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	var required []string
+
+	if err := addProperties(t, properties, &required); err != nil {
+		return nil, err
+	}
+
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s, nil
+}
+
+func addProperties(t reflect.Type, properties map[string]interface{}, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !reflectx.IsVisible(sf) {
+			continue
+		}
+		name, squash := reflectx.KeyName(sf)
+		if name == "-" {
+			continue
+		}
+		if squash {
+			if err := addProperties(sf.Type, properties, required); err != nil {
+				return err
+			}
+			continue
+		}
+
+		prop, err := typeSchema(sf.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if usage := sf.Tag.Get("usage"); usage != "" {
+			prop["description"] = usage
+		}
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			value, err := defaultSchemaValue(sf.Type, def)
+			if err != nil {
+				return fmt.Errorf("invalid default value for field %s: %w", name, err)
+			}
+			prop["default"] = value
+		}
+		if applyValidateRules(prop, sf.Type, sf.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+
+		properties[name] = prop
+	}
+	return nil
+}
+
+func typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case reflectx.IsDuration(t):
+		return map[string]interface{}{"type": "string", "pattern": durationPattern}, nil
+	case reflectx.IsTextUnmarshaler(t):
+		return map[string]interface{}{"type": "string"}, nil
+	case t.Kind() == reflect.Struct:
+		return objectSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", t)
+	}
+}
+
+// defaultSchemaValue parses a `default` tag the way ApplyDefaults does
+// and returns it in its JSON form. Durations and encoding.TextUnmarshaler
+// types (such as log.Level) are written in YAML as text, so the tag is kept as is.
+func defaultSchemaValue(t reflect.Type, def string) (interface{}, error) {
+	v := reflect.New(t).Elem()
+	if err := reflectx.SetString(v, def); err != nil {
+		return nil, err
+	}
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	if reflectx.IsDuration(base) || reflectx.IsTextUnmarshaler(base) {
+		return def, nil
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// applyValidateRules translates `validate` rules into schema constraints
+// and reports whether the field is required.
+func applyValidateRules(prop map[string]interface{}, t reflect.Type, tag string) (required bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			for _, key := range boundKeys(t.Kind(), name) {
+				prop[key] = n
+			}
+		case "oneof":
+			var enum []interface{}
+			for _, option := range strings.Fields(param) {
+				enum = append(enum, option)
+				if t.Kind() != reflect.String {
+					if n, err := strconv.ParseFloat(option, 64); err == nil {
+						enum[len(enum)-1] = n
+					}
+				}
+			}
+			prop["enum"] = enum
+		}
+	}
+	return required
+}
+
+func boundKeys(kind reflect.Kind, rule string) []string {
+	var prefix string
+	switch kind {
+	case reflect.String:
+		prefix = "Length"
+	case reflect.Slice, reflect.Array:
+		prefix = "Items"
+	case reflect.Map:
+		prefix = "Properties"
+	default:
+		switch rule {
+		case "min":
+			return []string{"minimum"}
+		case "max":
+			return []string{"maximum"}
+		default:
+			return nil
+		}
+	}
+
+	switch rule {
+	case "min":
+		return []string{"min" + prefix}
+	case "max":
+		return []string{"max" + prefix}
+	default:
+		return []string{"min" + prefix, "max" + prefix}
+	}
+}
@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shanth1/gotools/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaConfig struct {
+	Service string            `mapstructure:"service" usage:"Service name" validate:"required"`
+	Port    uint16            `mapstructure:"port" default:"8080" validate:"min=1,max=65535"`
+	Timeout time.Duration     `mapstructure:"timeout" default:"5s"`
+	Mode    string            `mapstructure:"mode" validate:"oneof=dev prod"`
+	Tags    []string          `mapstructure:"tags" default:"a,b"`
+	Labels  map[string]string `mapstructure:"labels"`
+	Level   log.Level         `mapstructure:"level" default:"debug"`
+	Log     log.Config        `mapstructure:"log"`
+	Ignored string            `mapstructure:"-"`
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := Schema(&schemaConfig{})
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, SchemaDraft, schema["$schema"])
+	assert.Equal(t, "schemaConfig", schema["title"])
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, false, schema["additionalProperties"])
+	assert.Equal(t, []interface{}{"service"}, schema["required"])
+
+	props := schema["properties"].(map[string]interface{})
+	assert.NotContains(t, props, "ignored")
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Service name"}, props["service"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "integer", "minimum": float64(1), "maximum": float64(65535), "default": float64(8080),
+	}, props["port"])
+	assert.Equal(t, "5s", props["timeout"].(map[string]interface{})["default"])
+	assert.Equal(t, []interface{}{"dev", "prod"}, props["mode"].(map[string]interface{})["enum"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "array", "items": map[string]interface{}{"type": "string"}, "default": []interface{}{"a", "b"},
+	}, props["tags"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "object", "additionalProperties": map[string]interface{}{"type": "string"},
+	}, props["labels"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "default": "debug"}, props["level"])
+
+	logProps := props["log"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Contains(t, logProps, "enable_caller")
+	assert.Equal(t, map[string]interface{}{"type": "boolean"}, logProps["console"])
+}
+
+func TestSchema_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Schema(42)
+	assert.EqualError(t, err, "expected a struct or a pointer to a struct, but got int")
+
+	type badDefault struct {
+		Port int `default:"eighty"`
+	}
+	_, err = Schema(badDefault{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid default value for field port")
+
+	type unsupported struct {
+		Ch chan int
+	}
+	_, err = Schema(unsupported{})
+	assert.EqualError(t, err, "field Ch: unsupported type: chan int")
+}
@@ -0,0 +1,40 @@
+package conf
+
+import (
+	"github.com/shanth1/gotools/internal/secrets"
+)
+
+// Resolver resolves secret references found in configuration values.
+//
+// Resolve returns the secret referenced by ref.
+// ok is false when ref is not a reference handled by the resolver,
+// in which case the value is passed to the next resolver or kept as is.
+type Resolver = secrets.Resolver
+
+// ResolverFunc adapts an ordinary function to the Resolver interface.
+type ResolverFunc = secrets.ResolverFunc
+
+// FileResolver resolves `file:///run/secrets/name` references to the content
+// of the file, without the trailing newline.
+type FileResolver = secrets.FileResolver
+
+// EnvResolver resolves `${ENV:NAME}` references to the value of the environment variable NAME.
+// Its Lookup field replaces os.LookupEnv if set.
+type EnvResolver = secrets.EnvResolver
+
+// DefaultResolvers returns the resolvers used when none are configured:
+// FileResolver and EnvResolver.
+func DefaultResolvers() []Resolver {
+	return secrets.DefaultResolvers()
+}
+
+// ResolveSecrets replaces secret references in the string fields of a struct
+// (including string slices and string map values) with the values they point to.
+// A value is a reference only if it matches a resolver as a whole.
+// Resolvers are tried in order; DefaultResolvers are used if none are given.
+//
+// Errors name the field and never contain the resolved secret.
+// Loaders only call it with the WithSecrets option.
+func ResolveSecrets(cfgPtr interface{}, resolvers ...Resolver) error {
+	return secrets.Resolve(cfgPtr, resolvers...)
+}
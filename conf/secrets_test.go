@@ -0,0 +1,120 @@
+package conf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretsConfig struct {
+	Password string            `mapstructure:"password"`
+	Token    string            `mapstructure:"token"`
+	Plain    string            `mapstructure:"plain"`
+	Keys     []string          `mapstructure:"keys"`
+	Headers  map[string]string `mapstructure:"headers"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Run("file and env references", func(t *testing.T) {
+		secretPath := createTestFile(t, "smtp_pass", "s3cr3t\n")
+		t.Setenv("TEST_SECRETS_TOKEN", "bot-token")
+
+		cfg := secretsConfig{
+			Password: "file://" + secretPath,
+			Token:    "${ENV:TEST_SECRETS_TOKEN}",
+			Plain:    "literal",
+			Keys:     []string{"${ENV:TEST_SECRETS_TOKEN}", "other"},
+			Headers:  map[string]string{"Authorization": "${ENV:TEST_SECRETS_TOKEN}"},
+		}
+		require.NoError(t, ResolveSecrets(&cfg))
+
+		assert.Equal(t, "s3cr3t", cfg.Password)
+		assert.Equal(t, "bot-token", cfg.Token)
+		assert.Equal(t, "literal", cfg.Plain)
+		assert.Equal(t, []string{"bot-token", "other"}, cfg.Keys)
+		assert.Equal(t, map[string]string{"Authorization": "bot-token"}, cfg.Headers)
+	})
+
+	t.Run("pointer sections and list elements", func(t *testing.T) {
+		t.Setenv("TEST_SECRETS_TOKEN", "bot-token")
+		type endpoint struct {
+			Token string `mapstructure:"token"`
+		}
+		type config struct {
+			Primary   *endpoint  `mapstructure:"primary"`
+			Fallbacks []endpoint `mapstructure:"fallbacks"`
+		}
+
+		cfg := config{
+			Primary:   &endpoint{Token: "${ENV:TEST_SECRETS_TOKEN}"},
+			Fallbacks: []endpoint{{Token: "literal"}, {Token: "${ENV:TEST_SECRETS_TOKEN}"}},
+		}
+		require.NoError(t, ResolveSecrets(&cfg))
+
+		assert.Equal(t, "bot-token", cfg.Primary.Token)
+		assert.Equal(t, []endpoint{{Token: "literal"}, {Token: "bot-token"}}, cfg.Fallbacks)
+	})
+
+	t.Run("missing env variable", func(t *testing.T) {
+		cfg := secretsConfig{Token: "${ENV:TEST_SECRETS_MISSING}"}
+		err := ResolveSecrets(&cfg)
+		assert.EqualError(t, err, "error resolving secret for field token: environment variable TEST_SECRETS_MISSING is not set")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg := secretsConfig{Password: "file:///non-existent/secret"}
+		err := ResolveSecrets(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error resolving secret for field password: read secret file")
+	})
+
+	t.Run("resolved secrets are not echoed in load errors", func(t *testing.T) {
+		type portConfig struct {
+			Password string `mapstructure:"password" validate:"max=3"`
+		}
+		secretPath := createTestFile(t, "pass", "very-secret-value")
+		path := createTestYAML(t, "password: file://"+secretPath+"\n")
+
+		var cfg portConfig
+		err := Load(path, &cfg, WithSecrets())
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "very-secret-value")
+	})
+
+	t.Run("custom resolver during load", func(t *testing.T) {
+		vault := ResolverFunc(func(ref string) (string, bool, error) {
+			name, ok := strings.CutPrefix(ref, "vault://")
+			if !ok {
+				return "", false, nil
+			}
+			if name != "smtp" {
+				return "", true, errors.New("secret not found")
+			}
+			return "from-vault", true, nil
+		})
+		path := createTestYAML(t, "password: vault://smtp\nplain: literal\n")
+
+		var cfg secretsConfig
+		require.NoError(t, Load(path, &cfg, WithSecrets(vault)))
+		assert.Equal(t, "from-vault", cfg.Password)
+		assert.Equal(t, "literal", cfg.Plain)
+	})
+
+	t.Run("not resolved without WithSecrets", func(t *testing.T) {
+		type dsnConfig struct {
+			DSN string `mapstructure:"dsn"`
+		}
+		dbPath := createTestFile(t, "app.db", "binary content")
+		path := createTestYAML(t, "dsn: file://"+dbPath+"\n")
+
+		var cfg dsnConfig
+		require.NoError(t, Load(path, &cfg))
+		assert.Equal(t, "file://"+dbPath, cfg.DSN)
+
+		require.NoError(t, Load(path, &cfg, WithSecrets()))
+		assert.Equal(t, "binary content", cfg.DSN)
+	})
+}
@@ -0,0 +1,224 @@
+package conf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Source provides a raw configuration document to LoadFrom.
+// Implement it to load configuration from custom backends.
+type Source interface {
+	// Name identifies the source in errors. If Read returns no format,
+	// the format is detected from the extension of the name.
+	Name() string
+	// Read returns the document and, if known, its format.
+	Read() (data []byte, format Format, err error)
+}
+
+// File returns a Source reading the file at path.
+func File(path string) Source {
+	return fileSource{path: path}
+}
+
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) Name() string { return s.path }
+
+func (s fileSource) Read() ([]byte, Format, error) {
+	data, err := os.ReadFile(s.path)
+	return data, "", err
+}
+
+// FS returns a Source reading the named file from fsys,
+// e.g. a default config embedded in the binary with embed.FS.
+func FS(fsys fs.FS, name string) Source {
+	return fsSource{fsys: fsys, name: name}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	name string
+}
+
+func (s fsSource) Name() string { return s.name }
+
+func (s fsSource) Read() ([]byte, Format, error) {
+	data, err := fs.ReadFile(s.fsys, s.name)
+	return data, "", err
+}
+
+// Bytes returns a Source holding an in-memory document in the given format.
+func Bytes(data []byte, format Format) Source {
+	return bytesSource{data: data, format: format}
+}
+
+type bytesSource struct {
+	data   []byte
+	format Format
+}
+
+func (s bytesSource) Name() string { return "<bytes>" }
+
+func (s bytesSource) Read() ([]byte, Format, error) {
+	return s.data, s.format, nil
+}
+
+// Reader returns a Source reading the whole document from r in the given format.
+func Reader(r io.Reader, format Format) Source {
+	return readerSource{r: r, format: format}
+}
+
+type readerSource struct {
+	r      io.Reader
+	format Format
+}
+
+func (s readerSource) Name() string { return "<reader>" }
+
+func (s readerSource) Read() ([]byte, Format, error) {
+	data, err := io.ReadAll(s.r)
+	return data, s.format, err
+}
+
+const (
+	// defaultHTTPTimeout bounds the requests of HTTP sources created without a client.
+	defaultHTTPTimeout = 30 * time.Second
+	// maxHTTPSize is the maximum size of a document fetched by an HTTP source.
+	maxHTTPSize = 10 << 20
+)
+
+// HTTP returns a Source fetching the document with a GET request to rawURL.
+// The format is taken from the Content-Type header, or the URL path extension.
+// If client is nil, a client with a 30 second timeout is used.
+// Documents larger than 10 MiB are rejected.
+func HTTP(rawURL string, client *http.Client) Source {
+	return HTTPContext(context.Background(), rawURL, client)
+}
+
+// HTTPContext is like HTTP, but the request is bound to ctx,
+// e.g. to abort loading on shutdown.
+func HTTPContext(ctx context.Context, rawURL string, client *http.Client) Source {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return httpSource{ctx: ctx, url: rawURL, client: client}
+}
+
+type httpSource struct {
+	ctx    context.Context
+	url    string
+	client *http.Client
+}
+
+func (s httpSource) Name() string { return s.url }
+
+var mimeToFormat = map[string]Format{
+	"application/json":   FormatJSON,
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/x-yaml":        FormatYAML,
+	"application/toml":   FormatTOML,
+}
+
+func (s httpSource) Read() ([]byte, Format, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("unexpected status fetching %s: %s", s.url, resp.Status)
+	}
+
+	// One byte more than the limit tells a document of exactly maxHTTPSize from a larger one.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxHTTPSize {
+		return nil, "", fmt.Errorf("document fetched from %s exceeds %d bytes", s.url, maxHTTPSize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if format, ok := mimeToFormat[mediaType]; ok {
+		return data, format, nil
+	}
+	if u, err := url.Parse(s.url); err == nil {
+		if format, err := FormatFromPath(u.Path); err == nil {
+			return data, format, nil
+		}
+	}
+	return data, "", nil
+}
+
+// LoadFrom reads configuration from src into a struct.
+// It behaves like Load, except that WithProfile does not apply:
+// the format comes from WithFormat, the source itself, or the extension of its name.
+//
+// Example: err := conf.LoadFrom(conf.FS(embeddedFS, "defaults.yaml"), &cfg)
+func LoadFrom(src Source, cfgPtr interface{}, opts ...option) error {
+	val := reflect.ValueOf(cfgPtr)
+
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
+	}
+
+	o := newOptions(opts...)
+	v := viper.New()
+	if err := mergeSource(v, src, o); err != nil {
+		return err
+	}
+
+	report, err := o.decode(v, val.Elem())
+	if err != nil {
+		return err
+	}
+
+	return o.finish(cfgPtr, report)
+}
+
+// mergeSource reads src and deep-merges its settings into v.
+func mergeSource(v *viper.Viper, src Source, o *options) error {
+	data, format, err := src.Read()
+	if err != nil {
+		return fmt.Errorf("error reading config file %q: %w", src.Name(), err)
+	}
+
+	if o.format != "" {
+		format = o.format
+	}
+	if format == "" {
+		// Sources without a known extension (e.g. "config" or "app.cfg") are read as YAML.
+		if format, err = FormatFromPath(src.Name()); err != nil {
+			format = FormatYAML
+		}
+	}
+	if err := format.validate(); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	v.SetConfigType(string(format))
+	if err := v.MergeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("error reading config file %q as %s: %w", src.Name(), format, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package conf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFrom(t *testing.T) {
+	t.Parallel()
+
+	want := TestFormatConfig{Service: "my-app", Port: 8080, Enabled: true}
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		path := createTestFile(t, "config.toml", "service = \"my-app\"\nport = 8080\nenabled = true\n")
+
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(File(path), &cfg))
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("fs", func(t *testing.T) {
+		t.Parallel()
+		fsys := fstest.MapFS{
+			"defaults/config.yaml": {Data: []byte("service: my-app\nport: 8080\nenabled: true\n")},
+		}
+
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(FS(fsys, "defaults/config.yaml"), &cfg))
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(Bytes([]byte(`{"service":"my-app","port":8080,"enabled":true}`), FormatJSON), &cfg))
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		src := Reader(strings.NewReader("SERVICE=my-app\nPORT=8080\nENABLED=true\n"), FormatDotenv)
+		require.NoError(t, LoadFrom(src, &cfg))
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("bytes without format", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(Bytes([]byte("service: my-app"), ""), &cfg), "read as yaml")
+		assert.Equal(t, "my-app", cfg.Service)
+
+		require.NoError(t, LoadFrom(Bytes([]byte(`{"port": 1}`), ""), &cfg, WithFormat(FormatJSON)))
+		assert.Equal(t, 1, cfg.Port)
+	})
+
+	t.Run("missing fs file", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		err := LoadFrom(FS(fstest.MapFS{}, "config.yaml"), &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `error reading config file "config.yaml"`)
+	})
+
+	t.Run("target is not a pointer to struct", func(t *testing.T) {
+		t.Parallel()
+		var i int
+		err := LoadFrom(Bytes(nil, FormatYAML), &i)
+		assert.EqualError(t, err, "expected a pointer to a struct, but got *int")
+	})
+}
+
+func TestLoadFrom_HTTP(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"service":"remote","port":9000}`))
+	})
+	mux.HandleFunc("/config.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("service: remote-yaml\n"))
+	})
+	mux.HandleFunc("/large.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("service: " + strings.Repeat("x", maxHTTPSize) + "\n"))
+	})
+	mux.HandleFunc("/slow.yaml", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Run("format from content type", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(HTTP(server.URL+"/config", server.Client()), &cfg))
+		assert.Equal(t, "remote", cfg.Service)
+		assert.Equal(t, 9000, cfg.Port)
+	})
+
+	t.Run("format from url path", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		require.NoError(t, LoadFrom(HTTP(server.URL+"/config.yaml?rev=2", nil), &cfg))
+		assert.Equal(t, "remote-yaml", cfg.Service)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		err := LoadFrom(HTTP(server.URL+"/missing.yaml", server.Client()), &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status")
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("document too large", func(t *testing.T) {
+		t.Parallel()
+		var cfg TestFormatConfig
+		err := LoadFrom(HTTP(server.URL+"/large.yaml", server.Client()), &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds")
+	})
+
+	t.Run("context", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var cfg TestFormatConfig
+		err := LoadFrom(HTTPContext(ctx, server.URL+"/slow.yaml", server.Client()), &cfg)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("default client has a timeout", func(t *testing.T) {
+		t.Parallel()
+		src, ok := HTTP(server.URL+"/config", nil).(httpSource)
+		require.True(t, ok)
+		assert.Equal(t, defaultHTTPTimeout, src.client.Timeout)
+	})
+}
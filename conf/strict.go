@@ -0,0 +1,139 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// UnknownKeysError lists the keys of a configuration file that do not match
+// any field of the config struct. It is returned in strict mode.
+type UnknownKeysError struct {
+	Keys []string // Dotted paths of the unknown keys (e.g. "log.enable_caler")
+}
+
+func (e *UnknownKeysError) Error() string {
+	return "unknown config keys: " + strings.Join(e.Keys, ", ")
+}
+
+// WithUnsetFields registers fn to be called after loading with the dotted keys
+// of the fields that received no value from any source, neither the file
+// nor a `default` tag (nor env or flags with LoadLayered).
+// Use it to warn about configuration drift at startup.
+func WithUnsetFields(fn func(keys []string)) option {
+	return func(o *options) {
+		o.onUnset = fn
+	}
+}
+
+// unknownKeys returns the sorted keys of settings that cannot be decoded into
+// type t. The elements of lists of structs are checked against the element
+// type, and their keys are indexed (e.g. "items[0].nmae").
+func unknownKeys(settings map[string]interface{}, t reflect.Type) []string {
+	unknown := unknownIn(reflect.ValueOf(settings), t, "")
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownIn returns the keys under the value v, found at path, that cannot be
+// decoded into a value of type t.
+func unknownIn(v reflect.Value, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	var unknown []string
+	switch {
+	case t.Kind() == reflect.Interface:
+	case v.Kind() == reflect.Map && (t.Kind() == reflect.Map || t.Kind() == reflect.Struct && !reflectx.IsLeaf(t)):
+		iter := v.MapRange()
+		for iter.Next() {
+			name := fmt.Sprint(iter.Key().Interface())
+			keyPath := joinKey(path, name)
+			if t.Kind() == reflect.Map {
+				unknown = append(unknown, unknownIn(iter.Value(), t.Elem(), keyPath)...)
+				continue
+			}
+			ft, ok := fieldType(t, name)
+			if !ok {
+				unknown = append(unknown, leafKeys(iter.Value(), keyPath)...)
+				continue
+			}
+			unknown = append(unknown, unknownIn(iter.Value(), ft, keyPath)...)
+		}
+	case v.Kind() == reflect.Map:
+		// A section where the field expects a single value.
+		unknown = leafKeys(v, path)
+	case v.Kind() == reflect.Slice && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for i := 0; i < v.Len(); i++ {
+			unknown = append(unknown, unknownIn(v.Index(i), t.Elem(), path+"["+strconv.Itoa(i)+"]")...)
+		}
+	}
+	return unknown
+}
+
+// fieldType returns the type of the field of struct t decoded from the key name.
+func fieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !reflectx.IsVisible(sf) {
+			continue
+		}
+		key, squash := reflectx.KeyName(sf)
+		if key == "-" {
+			continue
+		}
+		if squash {
+			if ft, ok := fieldType(sf.Type, name); ok {
+				return ft, true
+			}
+			continue
+		}
+		if strings.EqualFold(key, name) {
+			return sf.Type, true
+		}
+	}
+	return nil, false
+}
+
+// leafKeys returns the paths of the values under v, which is found at path.
+func leafKeys(v reflect.Value, path string) []string {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map || v.Len() == 0 {
+		return []string{path}
+	}
+	var keys []string
+	iter := v.MapRange()
+	for iter.Next() {
+		keys = append(keys, leafKeys(iter.Value(), joinKey(path, fmt.Sprint(iter.Key().Interface())))...)
+	}
+	return keys
+}
+
+func joinKey(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// unsetKeys returns the sorted keys of the report that no source supplied.
+func (r Report) unsetKeys() []string {
+	var keys []string
+	for key, origin := range r {
+		if origin == OriginUnset {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
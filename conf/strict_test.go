@@ -0,0 +1,133 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shanth1/gotools/log"
+	"github.com/shanth1/gotools/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictBase struct {
+	Version string `mapstructure:"version"`
+}
+
+type strictConfig struct {
+	strictBase `mapstructure:",squash"`
+	Service    string                         `mapstructure:"service"`
+	Port       int                            `mapstructure:"port" default:"8080"`
+	Log        log.Config                     `mapstructure:"log"`
+	Labels     map[string]string              `mapstructure:"labels"`
+	DBs        map[string]map[string]int      `mapstructure:"dbs"`
+	Extra      map[string]interface{}         `mapstructure:"extra"`
+	Hosts      []string                       `mapstructure:"hosts"`
+	Routes     map[string]strictBase          `mapstructure:"routes"`
+	Nested     struct{ Deep struct{ X int } } `mapstructure:"nested"`
+	Items      []strictBase                   `mapstructure:"items"`
+}
+
+func TestLoad_Strict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown keys are ignored by default", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "service: my-app\nprot: 8080\n")
+
+		var cfg strictConfig
+		require.NoError(t, Load(path, &cfg))
+	})
+
+	t.Run("reports every unknown key with its path", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, `
+service: my-app
+prot: 8080
+log:
+  level: debug
+  enable_caler: true
+  components:
+    - name: db
+      levle: debug
+hosts: [a, b]
+items:
+  - version: v1
+  - version: v2
+    verison: typo
+port:
+  value: 1
+nested:
+  deep:
+    x: 1
+    y: 2
+`)
+
+		var cfg strictConfig
+		err := Load(path, &cfg, WithStrict())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ops.KindInvalid)
+
+		var unknown *UnknownKeysError
+		require.True(t, errors.As(err, &unknown))
+		assert.Equal(t, []string{"items[1].verison", "log.components[0].levle", "log.enable_caler", "nested.deep.y", "port.value", "prot"}, unknown.Keys)
+		assert.Contains(t, err.Error(), "unknown config keys: items[1].verison, log.components[0].levle, log.enable_caler, nested.deep.y, port.value, prot")
+	})
+
+	t.Run("maps, squashed structs and case accept any known key", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, `
+version: v1
+SERVICE: my-app
+labels:
+  team: core
+dbs:
+  primary:
+    port: 5432
+extra:
+  anything:
+    goes: true
+routes:
+  api:
+    version: v2
+items:
+  - VERSION: v3
+`)
+
+		var cfg strictConfig
+		require.NoError(t, Load(path, &cfg, WithStrict()))
+		assert.Equal(t, "v1", cfg.Version)
+		assert.Equal(t, "v2", cfg.Routes["api"].Version)
+		assert.Equal(t, []strictBase{{Version: "v3"}}, cfg.Items)
+	})
+
+	t.Run("layered loader", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "sevice: typo\n")
+
+		var cfg strictConfig
+		_, err := LoadLayered(path, nil, &cfg, WithStrict())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown config keys: sevice")
+	})
+}
+
+func TestWithUnsetFields(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Service string `mapstructure:"service"`
+		Port    int    `mapstructure:"port" default:"8080"`
+		Log     struct {
+			Level string `mapstructure:"level"`
+		} `mapstructure:"log"`
+	}
+	path := createTestYAML(t, "service: my-app\n")
+
+	var unset []string
+	var cfg config
+	err := Load(path, &cfg, WithUnsetFields(func(keys []string) { unset = keys }))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log.level"}, unset)
+}
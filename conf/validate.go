@@ -0,0 +1,34 @@
+package conf
+
+import (
+	"github.com/shanth1/gotools/internal/validate"
+)
+
+// Validator is implemented by config structs that check their own consistency.
+// Validate calls it after all `validate` tag rules have passed.
+type Validator = validate.Validator
+
+// FieldError describes a validation rule violated by a single config field.
+type FieldError = validate.FieldError
+
+// ValidationErrors holds every violation found in a config struct.
+type ValidationErrors = validate.Errors
+
+// Validate checks a config struct against the rules in its `validate` tags
+// and, if they all pass, calls its Validate method when it implements Validator.
+//
+// Violations are reported all at once as an *ops.Error of kind ops.KindInvalid
+// wrapping ValidationErrors, which can be extracted with errors.As.
+// Values are never included in the report, so secrets do not leak into logs.
+//
+// Supported rules (comma-separated):
+// - `required`: the field must not be the zero value.
+// - `omitempty`: skip the remaining rules if the field is the zero value.
+// - `min=N`, `max=N`: bounds for numbers, or for the length of strings, slices and maps.
+// - `len=N`: exact length of strings, slices and maps.
+// - `oneof=a b c`: the field must be one of the space-separated values.
+//
+// Example: `validate:"required,min=1,max=65535"`
+func Validate(cfgPtr interface{}) error {
+	return validate.Struct("conf.Validate", cfgPtr)
+}
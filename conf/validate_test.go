@@ -0,0 +1,140 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shanth1/gotools/notify"
+	"github.com/shanth1/gotools/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateLogConfig struct {
+	Level string `mapstructure:"level" validate:"omitempty,oneof=debug info warn"`
+}
+
+type validateConfig struct {
+	Host  string            `mapstructure:"host" validate:"required"`
+	Port  int               `mapstructure:"port" validate:"required,min=1,max=65535"`
+	Name  string            `mapstructure:"name" validate:"min=3,max=10"`
+	Tags  []string          `mapstructure:"tags" validate:"len=2"`
+	Log   validateLogConfig `mapstructure:"log"`
+	Plain string            `mapstructure:"plain"`
+}
+
+type selfValidatingConfig struct {
+	Min int `mapstructure:"min"`
+	Max int `mapstructure:"max"`
+}
+
+func (c *selfValidatingConfig) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("min must not exceed max")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		cfg := validateConfig{Host: "localhost", Port: 8080, Name: "service", Tags: []string{"a", "b"}}
+		assert.NoError(t, Validate(&cfg))
+	})
+
+	t.Run("reports all violations", func(t *testing.T) {
+		t.Parallel()
+		cfg := validateConfig{Port: 70000, Name: "ab", Tags: []string{"a"}, Log: validateLogConfig{Level: "trace"}}
+
+		err := Validate(&cfg)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ops.KindInvalid)
+
+		var violations ValidationErrors
+		require.True(t, errors.As(err, &violations))
+		assert.Equal(t, ValidationErrors{
+			{Field: "host", Rule: "required", Message: "is required"},
+			{Field: "port", Rule: "max", Param: "65535", Message: "must be at most 65535"},
+			{Field: "name", Rule: "min", Param: "3", Message: "must be at least length 3"},
+			{Field: "tags", Rule: "len", Param: "2", Message: "must have length 2"},
+			{Field: "log.level", Rule: "oneof", Param: "debug info warn", Message: "must be one of [debug, info, warn]"},
+		}, violations)
+		assert.Contains(t, err.Error(), "host: is required; port: must be at most 65535")
+	})
+
+	t.Run("omitempty skips zero values", func(t *testing.T) {
+		t.Parallel()
+		cfg := validateConfig{Host: "localhost", Port: 1, Name: "service", Tags: []string{"a", "b"}}
+		assert.NoError(t, Validate(&cfg))
+	})
+
+	t.Run("validator method", func(t *testing.T) {
+		t.Parallel()
+		err := Validate(&selfValidatingConfig{Min: 2, Max: 1})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ops.KindInvalid)
+		assert.Contains(t, err.Error(), "min must not exceed max")
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		t.Parallel()
+		type badConfig struct {
+			Port int `validate:"between=1"`
+		}
+		err := Validate(&badConfig{})
+		assert.EqualError(t, err, `unknown validation rule "between" on field port`)
+	})
+
+	t.Run("runs after load", func(t *testing.T) {
+		t.Parallel()
+		path := createTestYAML(t, "host: localhost\nport: 0\n")
+
+		var cfg validateConfig
+		err := Load(path, &cfg)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ops.KindInvalid)
+		assert.Contains(t, err.Error(), "port: is required")
+	})
+
+	t.Run("pointer sections and list elements", func(t *testing.T) {
+		t.Parallel()
+		type item struct {
+			Name string `mapstructure:"name" validate:"required"`
+		}
+		type inner struct {
+			Port int `mapstructure:"port" validate:"required,max=10"`
+		}
+		type nestedConfig struct {
+			Ptr   *inner `mapstructure:"ptr"`
+			Items []item `mapstructure:"items" validate:"min=1"`
+		}
+		path := createTestYAML(t, "ptr:\n  port: 99\nitems:\n  - name: a\n  - name: \"\"\n")
+
+		var cfg nestedConfig
+		err := Load(path, &cfg)
+		require.Error(t, err)
+
+		var violations ValidationErrors
+		require.True(t, errors.As(err, &violations))
+		assert.Equal(t, ValidationErrors{
+			{Field: "ptr.port", Rule: "max", Param: "10", Message: "must be at most 10"},
+			{Field: "items[1].name", Rule: "required", Message: "is required"},
+		}, violations)
+
+		assert.NoError(t, Validate(&nestedConfig{Items: []item{{Name: "a"}}}), "a nil pointer section is not validated")
+	})
+
+	t.Run("library configs are optional", func(t *testing.T) {
+		t.Parallel()
+		type serviceConfig struct {
+			Service string             `mapstructure:"service"`
+			Email   notify.EmailConfig `mapstructure:"email"`
+		}
+		path := createTestYAML(t, "service: bot\n")
+
+		var cfg serviceConfig
+		require.NoError(t, Load(path, &cfg), "an unset EmailConfig must not fail validation")
+	})
+}
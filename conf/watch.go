@@ -0,0 +1,195 @@
+package conf
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces the bursts of events editors emit for a single save.
+const reloadDelay = 100 * time.Millisecond
+
+// Watcher keeps a configuration struct in sync with its file.
+//
+// On every change the file is decoded into a fresh value of T, validated
+// and delivered to subscribers. If the new file is broken, the previous
+// value is kept and the error is reported to the error handlers instead.
+type Watcher[T any] struct {
+	path string
+	opts []option
+
+	mu          sync.RWMutex
+	current     T
+	subscribers []func(cfg T)
+	errHandlers []func(err error)
+
+	fsw       *fsnotify.Watcher
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+}
+
+// Watch loads the configuration file at path into a value of T
+// and starts watching the file for changes.
+// T must be a struct type. Options are the same as for Load;
+// with WithProfile, changes to the overlay file trigger a reload too.
+// Call Close to stop watching.
+func Watch[T any](path string, opts ...option) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		path: path,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+
+	cfg, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.current = cfg
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating config watcher: %w", err)
+	}
+	// Watch the directory to pick up atomic saves and symlink swaps (e.g. k8s ConfigMaps).
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		_ = fsw.Close()
+		return nil, fmt.Errorf("error watching config file: %w", err)
+	}
+	w.fsw = fsw
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// Current returns the latest valid configuration.
+func (w *Watcher[T]) Current() T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Subscribe registers fn to be called with every new valid configuration.
+func (w *Watcher[T]) Subscribe(fn func(cfg T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// OnError registers fn to be called when a changed file cannot be loaded.
+// The previous configuration stays current in that case.
+func (w *Watcher[T]) OnError(fn func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.errHandlers = append(w.errHandlers, fn)
+}
+
+// Close stops watching the file. Subscribers are not called after Close returns.
+// It is safe to call Close several times, also concurrently.
+func (w *Watcher[T]) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closeErr = w.fsw.Close()
+		w.wg.Wait()
+	})
+	return w.closeErr
+}
+
+func (w *Watcher[T]) run() {
+	defer w.wg.Done()
+
+	configFiles := map[string]bool{filepath.Clean(w.path): true}
+	if profile := newOptions(w.opts...).profile; profile != "" {
+		configFiles[filepath.Clean(ProfilePath(w.path, profile))] = true
+	}
+	realConfigFile, _ := filepath.EvalSymlinks(w.path)
+
+	var timer *time.Timer
+	var reload <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			currentConfigFile, _ := filepath.EvalSymlinks(w.path)
+			modified := configFiles[filepath.Clean(event.Name)] &&
+				(event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+			swapped := currentConfigFile != "" && currentConfigFile != realConfigFile
+			if !modified && !swapped {
+				continue
+			}
+			realConfigFile = currentConfigFile
+
+			if timer == nil {
+				timer = time.NewTimer(reloadDelay)
+			} else {
+				timer.Reset(reloadDelay)
+			}
+			reload = timer.C
+
+		case <-reload:
+			reload = nil
+			w.reload()
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.reportError(fmt.Errorf("error watching config file: %w", err))
+		}
+	}
+}
+
+func (w *Watcher[T]) reload() {
+	cfg, err := w.load()
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+
+	w.mu.Lock()
+	if reflect.DeepEqual(w.current, cfg) {
+		w.mu.Unlock()
+		return
+	}
+	w.current = cfg
+	subscribers := append([]func(cfg T){}, w.subscribers...)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(cfg)
+	}
+}
+
+func (w *Watcher[T]) reportError(err error) {
+	w.mu.RLock()
+	handlers := append([]func(err error){}, w.errHandlers...)
+	w.mu.RUnlock()
+
+	for _, fn := range handlers {
+		fn(err)
+	}
+}
+
+// load decodes the file into a fresh value of T.
+// Load validates it, so invalid values never reach subscribers.
+func (w *Watcher[T]) load() (T, error) {
+	var cfg T
+	err := Load(w.path, &cfg, w.opts...)
+	return cfg, err
+}
@@ -0,0 +1,95 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shanth1/gotools/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type watchConfig struct {
+	Level  string `mapstructure:"level"`
+	ChatID int64  `mapstructure:"chat_id"`
+}
+
+func (c watchConfig) Validate() error {
+	if c.ChatID == 0 {
+		return errors.New("chat_id is required")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	path := createTestYAML(t, "level: info\nchat_id: 1\n")
+
+	w, err := Watch[watchConfig](path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	assert.Equal(t, watchConfig{Level: "info", ChatID: 1}, w.Current())
+
+	updates := make(chan watchConfig, 1)
+	errs := make(chan error, 1)
+	w.Subscribe(func(cfg watchConfig) {
+		select {
+		case updates <- cfg:
+		default:
+		}
+	})
+	w.OnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("level: debug\nchat_id: 2\n"), 0644))
+	select {
+	case cfg := <-updates:
+		assert.Equal(t, watchConfig{Level: "debug", ChatID: 2}, cfg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config update")
+	}
+	assert.Equal(t, watchConfig{Level: "debug", ChatID: 2}, w.Current())
+
+	require.NoError(t, os.WriteFile(path, []byte("level: warn\n"), 0644))
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "chat_id is required")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config error")
+	}
+	assert.Equal(t, watchConfig{Level: "debug", ChatID: 2}, w.Current(), "broken file must keep the old value")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, w.Close())
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+}
+
+func TestWatch_InitialLoadError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Watch[watchConfig]("non-existent-file.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading config file")
+
+	path := createTestYAML(t, "level: info\n")
+	_, err = Watch[watchConfig](path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chat_id is required")
+	assert.ErrorIs(t, err, ops.KindInvalid)
+}
@@ -2,10 +2,14 @@ package env
 
 import (
 	"fmt"
+	"os"
 	"reflect"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
-	"github.com/joho/godotenv"
+	"github.com/shanth1/gotools/internal/reflectx"
+	"github.com/shanth1/gotools/internal/secrets"
+	"github.com/shanth1/gotools/internal/validate"
 )
 
 // LoadIntoStruct loads data from variables and env file into structure
@@ -13,21 +17,163 @@ import (
 //
 // Priority: System .env > File .env
 // If the path to the file is not specified, only system variables are read.
-func LoadIntoStruct(envPath string, cfgPtr interface{}) error {
-	val := reflect.ValueOf(cfgPtr)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
+// Fields set by neither a variable nor an `env-default` tag get the value of
+// their `default` tag (as in conf.Load).
+// With WithSecrets, secret references such as `file:///run/secrets/token` are
+// resolved. The struct is then checked against its `validate` tags with the
+// rules of conf.Validate.
+//
+// With WithAutoNames, fields without an `env` tag are read from variables
+// named after their path in the struct (e.g. APP_LOG_LEVEL).
+// Variables are parsed by cleanenv, so `env-layout`, `env-upd` and the
+// cleanenv.Setter and cleanenv.Updater interfaces work as documented there.
+// By default the file is loaded into the process environment; with
+// WithIsolatedEnv or WithLookup it is only visible to this call.
+func LoadIntoStruct(envPath string, cfgPtr interface{}, opts ...option) error {
+	var files []File
+	if envPath != "" {
+		files = []File{{Path: envPath}}
+	}
+	_, err := load(files, cfgPtr, newOptions(opts...))
+	return err
+}
+
+func load(files []File, cfgPtr interface{}, o *options) (Report, error) {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return nil, err
 	}
 
-	if envPath != "" {
-		if err := godotenv.Load(envPath); err != nil {
-			return fmt.Errorf("read env file: %w", err)
+	lookup, err := o.fileLookup(files)
+	if err != nil {
+		return nil, err
+	}
+
+	report := Report{}
+	if o.autoNames {
+		if err := o.readAutoNames(elem, lookup, report); err != nil {
+			return nil, err
 		}
 	}
+	if err := o.readLayers(elem, lookup, report); err != nil {
+		return nil, err
+	}
 
 	if err := cleanenv.ReadEnv(cfgPtr); err != nil {
-		return fmt.Errorf("read environment variables: %w", err)
+		return nil, fmt.Errorf("read environment variables: %w", err)
+	}
+
+	// The shared `default` tags come last, for the fields that got no value
+	// from a variable or an `env-default` tag: an explicit "false" or "0" is kept.
+	if err := reflectx.ApplyDefaults(elem, explicitKeys(elem, report), nil); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if o.secrets {
+		resolvers := append(append([]Resolver{}, o.resolvers...), secrets.FileResolver{}, secrets.EnvResolver{Lookup: lookup.env})
+		if err := secrets.Resolve(cfgPtr, resolvers...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validate.Struct("env.Load", cfgPtr); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// explicitKeys returns the function reporting whether a field got its value
+// from a variable (recorded in report, both for the layers and for the process
+// environment read by cleanenv) or from an `env-default` tag.
+func explicitKeys(elem reflect.Value, report Report) func(key string) bool {
+	envDefaults := map[string]bool{}
+	_ = reflectx.Walk(elem, func(f reflectx.Field) error {
+		if _, ok := f.StructField.Tag.Lookup("env-default"); ok {
+			envDefaults[f.Key()] = true
+		}
+		return nil
+	})
+	return func(key string) bool {
+		_, ok := report[key]
+		return ok || envDefaults[key]
+	}
+}
+
+// readLayers sets the fields with an `env` tag from the variables that
+// cleanenv does not see: those of isolated env files and of the WithLookup
+// function. Variables of the process environment are left to cleanenv, which
+// runs afterwards, so they take precedence and `env-default` and
+// `env-required` tags see the values set here. The source of every tagged
+// field found in any layer is recorded in report.
+func (o *options) readLayers(elem reflect.Value, lookup lookupFunc, report Report) error {
+	return reflectx.Walk(elem, func(f reflectx.Field) error {
+		if _, ok := f.StructField.Tag.Lookup("env"); !ok {
+			return nil
+		}
+		names := o.varNames(f)
+
+		for _, name := range names {
+			if _, ok := os.LookupEnv(name); ok {
+				_, source, _ := lookup(name)
+				report[f.Key()] = source
+				return nil
+			}
+		}
+
+		for _, name := range names {
+			value, source, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := setValue(f, value); err != nil {
+				return fmt.Errorf("invalid value in environment variable %s: %w", name, err)
+			}
+			report[f.Key()] = source
+			return nil
+		}
+		return nil
+	})
+}
+
+// setValue parses value into the field like cleanenv does: through the
+// cleanenv.Setter interface, with the `env-layout` tag for times, and split
+// on the `env-separator` tag for slices and maps.
+func setValue(f reflectx.Field, value string) error {
+	if setter, ok := f.Value.Interface().(cleanenv.Setter); ok {
+		return setter.SetValue(value)
+	}
+	if setter, ok := f.Value.Addr().Interface().(cleanenv.Setter); ok {
+		return setter.SetValue(value)
+	}
+
+	if layout, ok := f.StructField.Tag.Lookup("env-layout"); ok && f.Value.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		f.Value.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	return reflectx.SetStringSep(f.Value, value, separator(f))
+}
+
+// varNames returns the variables of a field in order of precedence: the
+// comma-separated names of its `env` tag, prefixed by the `env-prefix` tags
+// of its parents, or its derived name with WithAutoNames.
+func (o *options) varNames(f reflectx.Field) []string {
+	if _, ok := f.StructField.Tag.Lookup("env"); !ok {
+		if o.autoNames {
+			return []string{o.autoName(f)}
+		}
+		return nil
+	}
+	return f.EnvNames()
+}
+
+func separator(f reflectx.Field) string {
+	if sep, ok := f.StructField.Tag.Lookup("env-separator"); ok {
+		return sep
+	}
+	return ","
 }
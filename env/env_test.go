@@ -3,8 +3,12 @@ package env
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/shanth1/gotools/log"
+	"github.com/shanth1/gotools/ops"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -80,3 +84,323 @@ func TestLoadIntoStruct(t *testing.T) {
 		assert.EqualError(t, err, "expected a pointer to a struct, but got *int")
 	})
 }
+
+func TestLoadIntoStruct_Validation(t *testing.T) {
+	type ValidatedConfig struct {
+		Port int `env:"VALIDATED_PORT" validate:"required,max=65535"`
+	}
+
+	require.NoError(t, os.Setenv("VALIDATED_PORT", "70000"))
+	t.Cleanup(func() {
+		os.Unsetenv("VALIDATED_PORT")
+	})
+
+	var cfg ValidatedConfig
+	err := LoadIntoStruct("", &cfg)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ops.KindInvalid)
+	assert.Contains(t, err.Error(), "port: must be at most 65535")
+}
+
+func TestLoadIntoStruct_Secrets(t *testing.T) {
+	type SecretConfig struct {
+		Token string `env:"SECRET_TOKEN"`
+	}
+
+	secretPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(secretPath, []byte("bot-token\n"), 0600))
+	require.NoError(t, os.Setenv("SECRET_TOKEN", "file://"+secretPath))
+	t.Cleanup(func() {
+		os.Unsetenv("SECRET_TOKEN")
+	})
+
+	var cfg SecretConfig
+	require.NoError(t, LoadIntoStruct("", &cfg))
+	assert.Equal(t, "file://"+secretPath, cfg.Token, "secrets are only resolved with WithSecrets")
+
+	require.NoError(t, LoadIntoStruct("", &cfg, WithSecrets()))
+	assert.Equal(t, "bot-token", cfg.Token)
+}
+
+func TestLoadIntoStruct_DefaultTag(t *testing.T) {
+	type DefaultConfig struct {
+		Host    string        `env:"DEFAULT_TAG_HOST" default:"localhost"`
+		Timeout time.Duration `env:"DEFAULT_TAG_TIMEOUT" default:"5s"`
+	}
+
+	require.NoError(t, os.Setenv("DEFAULT_TAG_HOST", "db.from.system"))
+	t.Cleanup(func() {
+		os.Unsetenv("DEFAULT_TAG_HOST")
+	})
+
+	var cfg DefaultConfig
+	require.NoError(t, LoadIntoStruct("", &cfg))
+	assert.Equal(t, "db.from.system", cfg.Host)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+}
+
+func TestLoadIntoStruct_DefaultPrecedence(t *testing.T) {
+	type PrecedenceConfig struct {
+		FromVar        string `env:"PRECEDENCE_VAR" env-default:"env-default" default:"default"`
+		FromEnvDefault string `env:"PRECEDENCE_ENV_DEFAULT" env-default:"env-default" default:"default"`
+		FromDefault    string `env:"PRECEDENCE_DEFAULT" default:"default"`
+	}
+
+	t.Setenv("PRECEDENCE_VAR", "variable")
+
+	var cfg PrecedenceConfig
+	require.NoError(t, LoadIntoStruct("", &cfg))
+	assert.Equal(t, PrecedenceConfig{
+		FromVar:        "variable",
+		FromEnvDefault: "env-default",
+		FromDefault:    "default",
+	}, cfg)
+}
+
+func TestLoadIntoStruct_ExplicitZeroKeepsValue(t *testing.T) {
+	type ZeroConfig struct {
+		Debug   bool   `env:"ZERO_DEBUG" default:"true"`
+		Port    int    `env:"ZERO_PORT" default:"8080"`
+		Retries int    `env:"ZERO_RETRIES" env-default:"0" default:"3"`
+		Host    string `env:"ZERO_HOST" default:"localhost"`
+	}
+
+	t.Setenv("ZERO_DEBUG", "false")
+	t.Setenv("ZERO_PORT", "0")
+
+	t.Run("process environment", func(t *testing.T) {
+		var cfg ZeroConfig
+		require.NoError(t, LoadIntoStruct("", &cfg))
+		assert.Equal(t, ZeroConfig{Debug: false, Port: 0, Retries: 0, Host: "localhost"}, cfg)
+	})
+
+	t.Run("isolated file", func(t *testing.T) {
+		path := createTestEnvFile(t, "ZERO_HOST=\n")
+		var cfg ZeroConfig
+		require.NoError(t, LoadIntoStruct(path, &cfg, WithIsolatedEnv()))
+		assert.Equal(t, ZeroConfig{Debug: false, Port: 0, Retries: 0, Host: ""}, cfg)
+	})
+}
+
+func TestLoadIntoStruct_AutoNames(t *testing.T) {
+	type Section struct {
+		Host       string `mapstructure:"host"`
+		UDPAddress string
+		Debug      bool `env-default:"true"`
+	}
+	type AutoConfig struct {
+		Log      log.Config
+		Primary  Section `mapstructure:"primary"`
+		Explicit string  `env:"AUTO_EXPLICIT"`
+		Hosts    []string
+		Timeout  time.Duration `env-required:"true"`
+	}
+
+	vars := map[string]string{
+		"AUTO_LOG_LEVEL":             "debug",
+		"AUTO_LOG_ENABLE_CALLER":     "true",
+		"AUTO_PRIMARY_HOST":          "db.local",
+		"AUTO_PRIMARY_UDP_ADDRESS":   "127.0.0.1:5140",
+		"AUTO_EXPLICIT":              "tagged",
+		"AUTO_HOSTS":                 "a, b",
+		"AUTO_TIMEOUT":               "3s",
+		"AUTO__PRIMARY__HOST":        "db.double",
+		"AUTO__PRIMARY__UDP_ADDRESS": "10.0.0.1:5140",
+		"AUTO__TIMEOUT":              "1s",
+	}
+	for name, value := range vars {
+		require.NoError(t, os.Setenv(name, value))
+	}
+	t.Cleanup(func() {
+		for name := range vars {
+			os.Unsetenv(name)
+		}
+	})
+
+	t.Run("derives names from field paths", func(t *testing.T) {
+		var cfg AutoConfig
+		require.NoError(t, LoadIntoStruct("", &cfg, WithAutoNames("AUTO")))
+
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.True(t, cfg.Log.EnableCaller)
+		assert.Equal(t, "db.local", cfg.Primary.Host)
+		assert.Equal(t, "127.0.0.1:5140", cfg.Primary.UDPAddress)
+		assert.True(t, cfg.Primary.Debug, "env-default should apply to derived names")
+		assert.Equal(t, "tagged", cfg.Explicit)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		assert.Equal(t, 3*time.Second, cfg.Timeout)
+	})
+
+	t.Run("custom separator", func(t *testing.T) {
+		var cfg AutoConfig
+		require.NoError(t, LoadIntoStruct("", &cfg, WithAutoNames("AUTO"), WithSeparator("__")))
+
+		assert.Equal(t, "db.double", cfg.Primary.Host)
+		assert.Equal(t, "10.0.0.1:5140", cfg.Primary.UDPAddress)
+		assert.Equal(t, time.Second, cfg.Timeout)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var cfg AutoConfig
+		err := LoadIntoStruct("", &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Timeout")
+		assert.Empty(t, cfg.Primary.Host)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("BAD_TIMEOUT", "soon")
+
+		var cfg AutoConfig
+		err := LoadIntoStruct("", &cfg, WithAutoNames("BAD"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid value in environment variable BAD_TIMEOUT")
+	})
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Host":         "host",
+		"DBHost":       "db_host",
+		"UDPAddress":   "udp_address",
+		"EnableCaller": "enable_caller",
+		"APIKey":       "api_key",
+		"ID":           "id",
+		"Port2":        "port2",
+		"V2Endpoint":   "v2_endpoint",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, snakeCase(in), in)
+	}
+}
+
+func TestLoadIntoStruct_Isolated(t *testing.T) {
+	t.Parallel()
+
+	type IsolatedConfig struct {
+		Host  string `env:"ISOLATED_HOST"`
+		Port  int    `env:"ISOLATED_PORT"`
+		Token string `env:"ISOLATED_TOKEN"`
+	}
+
+	t.Run("file does not modify the process environment", func(t *testing.T) {
+		t.Parallel()
+		path := createTestEnvFile(t, "ISOLATED_HOST=db.from.file\nISOLATED_PORT=5432\n")
+
+		var cfg IsolatedConfig
+		require.NoError(t, LoadIntoStruct(path, &cfg, WithIsolatedEnv()))
+		assert.Equal(t, "db.from.file", cfg.Host)
+		assert.Equal(t, 5432, cfg.Port)
+
+		_, ok := os.LookupEnv("ISOLATED_HOST")
+		assert.False(t, ok)
+	})
+
+	t.Run("custom lookup wins over the file", func(t *testing.T) {
+		t.Parallel()
+		path := createTestEnvFile(t, "ISOLATED_HOST=db.from.file\nISOLATED_PORT=5432\nSECRET_VALUE=s3cr3t\n")
+		vars := map[string]string{
+			"ISOLATED_HOST":  "db.from.lookup",
+			"ISOLATED_TOKEN": "${ENV:SECRET_VALUE}",
+		}
+		lookup := func(name string) (string, bool) {
+			value, ok := vars[name]
+			return value, ok
+		}
+
+		var cfg IsolatedConfig
+		require.NoError(t, LoadIntoStruct(path, &cfg, WithLookup(lookup), WithSecrets()))
+		assert.Equal(t, "db.from.lookup", cfg.Host)
+		assert.Equal(t, 5432, cfg.Port)
+		assert.Equal(t, "s3cr3t", cfg.Token, "secret references should use the same lookup")
+
+		_, ok := os.LookupEnv("SECRET_VALUE")
+		assert.False(t, ok)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		t.Parallel()
+		var cfg IsolatedConfig
+		err := LoadIntoStruct("non-existent.env", &cfg, WithIsolatedEnv())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read env file")
+	})
+}
+
+func TestLoadIntoStruct_Tags(t *testing.T) {
+	t.Parallel()
+
+	type DB struct {
+		Host  string   `env:"HOST,HOSTNAME"`
+		Ports []int    `env:"PORTS" env-separator:";"`
+		User  string   `env:"USER" env-required:"true"`
+		Tags  []string `env:"TAGS" env-default:"a,b"`
+	}
+	type TagConfig struct {
+		DB DB `env-prefix:"DB_"`
+	}
+
+	lookup := func(vars map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			value, ok := vars[name]
+			return value, ok
+		}
+	}
+
+	t.Run("prefix, alternatives, separator and default", func(t *testing.T) {
+		t.Parallel()
+		var cfg TagConfig
+		err := LoadIntoStruct("", &cfg, WithLookup(lookup(map[string]string{
+			"DB_HOSTNAME": "db.local",
+			"DB_PORTS":    "5432;5433",
+			"DB_USER":     "admin",
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, DB{Host: "db.local", Ports: []int{5432, 5433}, User: "admin", Tags: []string{"a", "b"}}, cfg.DB)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		t.Parallel()
+		var cfg TagConfig
+		err := LoadIntoStruct("", &cfg, WithLookup(lookup(nil)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `field "User" is required`)
+	})
+}
+
+// upperString implements cleanenv.Setter.
+type upperString string
+
+func (s *upperString) SetValue(value string) error {
+	*s = upperString(strings.ToUpper(value))
+	return nil
+}
+
+func TestLoadIntoStruct_CleanenvTags(t *testing.T) {
+	type CleanenvConfig struct {
+		Name    upperString `env:"CLEANENV_NAME"`
+		Release time.Time   `env:"CLEANENV_RELEASE" env-layout:"2006-01-02"`
+		Region  string      `env:"CLEANENV_REGION" env-default:"eu"`
+	}
+
+	t.Run("process environment", func(t *testing.T) {
+		t.Setenv("CLEANENV_NAME", "bot")
+		t.Setenv("CLEANENV_RELEASE", "2024-05-01")
+
+		var cfg CleanenvConfig
+		require.NoError(t, LoadIntoStruct("", &cfg))
+		assert.Equal(t, upperString("BOT"), cfg.Name)
+		assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), cfg.Release)
+		assert.Equal(t, "eu", cfg.Region)
+	})
+
+	t.Run("isolated file", func(t *testing.T) {
+		path := createTestEnvFile(t, "CLEANENV_NAME=bot\nCLEANENV_RELEASE=2024-05-01\nCLEANENV_REGION=us\n")
+
+		var cfg CleanenvConfig
+		require.NoError(t, LoadIntoStruct(path, &cfg, WithIsolatedEnv()))
+		assert.Equal(t, upperString("BOT"), cfg.Name)
+		assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), cfg.Release)
+		assert.Equal(t, "us", cfg.Region, "file values take precedence over env-default")
+	})
+}
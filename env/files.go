@@ -0,0 +1,103 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/shanth1/gotools/consts"
+)
+
+// SourceSystem is the source reported for values read from the process
+// environment (or the function set with WithLookup) rather than from a file.
+const SourceSystem = "system"
+
+// File is an env file to load with LoadFiles.
+type File struct {
+	Path     string // Path of the file
+	Optional bool   // A missing optional file is skipped
+}
+
+// Report maps the dotted key of every field set from a variable (e.g. "db.host")
+// to the source of its value: the path of the file or SourceSystem.
+type Report map[string]string
+
+// StandardFiles returns the conventional env files in dir for the environment,
+// all optional, in order of increasing precedence:
+// .env, .env.<env> (if env is not empty) and .env.local.
+func StandardFiles(dir string, env consts.Env) []File {
+	files := []File{{Path: filepath.Join(dir, ".env"), Optional: true}}
+	if env != "" {
+		files = append(files, File{Path: filepath.Join(dir, ".env."+string(env)), Optional: true})
+	}
+	return append(files, File{Path: filepath.Join(dir, ".env.local"), Optional: true})
+}
+
+// LoadFiles is like LoadIntoStruct with several env files, and reports where
+// each value came from.
+//
+// Priority: System > later files > earlier files.
+// Missing files fail the load unless they are Optional.
+func LoadFiles(files []File, cfgPtr interface{}, opts ...option) (Report, error) {
+	return load(files, cfgPtr, newOptions(opts...))
+}
+
+// lookupFunc returns the value of a variable and the source that supplied it.
+type lookupFunc func(name string) (value, source string, ok bool)
+
+// fileLookup reads the env files and returns the lookup function that sees
+// the process environment, the WithLookup function and the files, in that order.
+// Unless isolated, file entries are also written to the process environment.
+func (o *options) fileLookup(files []File) (lookupFunc, error) {
+	fileVars := map[string]string{}
+	fileOf := map[string]string{}
+	for _, file := range files {
+		vars, err := godotenv.Read(file.Path)
+		if err != nil {
+			if file.Optional && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("read env file: %w", err)
+		}
+		for name, value := range vars {
+			fileVars[name] = value
+			fileOf[name] = file.Path
+		}
+	}
+
+	written := map[string]bool{}
+	if !o.isolated && o.lookup == nil {
+		for name, value := range fileVars {
+			if _, ok := os.LookupEnv(name); ok {
+				continue
+			}
+			if err := os.Setenv(name, value); err != nil {
+				return nil, fmt.Errorf("set environment variable %s: %w", name, err)
+			}
+			written[name] = true
+		}
+	}
+
+	return func(name string) (string, string, bool) {
+		if !written[name] {
+			if value, ok := os.LookupEnv(name); ok {
+				return value, SourceSystem, true
+			}
+		}
+		if o.lookup != nil {
+			if value, ok := o.lookup(name); ok {
+				return value, SourceSystem, true
+			}
+		}
+		value, ok := fileVars[name]
+		return value, fileOf[name], ok
+	}, nil
+}
+
+func (l lookupFunc) env(name string) (string, bool) {
+	value, _, ok := l(name)
+	return value, ok
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shanth1/gotools/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFiles(t *testing.T) {
+	t.Parallel()
+
+	type FilesConfig struct {
+		Host  string `env:"FILES_HOST"`
+		Port  int    `env:"FILES_PORT"`
+		Debug bool   `env:"FILES_DEBUG"`
+		User  string `env:"FILES_USER" env-default:"app"`
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	base := write(".env", "FILES_HOST=base\nFILES_PORT=5432\nFILES_DEBUG=false\n")
+	prod := write(".env.prod", "FILES_HOST=prod\nFILES_DEBUG=true\n")
+	local := write(".env.local", "FILES_DEBUG=false\n")
+
+	system := map[string]string{"FILES_HOST": "system"}
+	lookup := func(name string) (string, bool) {
+		value, ok := system[name]
+		return value, ok
+	}
+
+	t.Run("later files override earlier ones, system wins", func(t *testing.T) {
+		t.Parallel()
+		var cfg FilesConfig
+		report, err := LoadFiles(StandardFiles(dir, consts.EnvProd), &cfg, WithLookup(lookup))
+		require.NoError(t, err)
+
+		assert.Equal(t, FilesConfig{Host: "system", Port: 5432, Debug: false, User: "app"}, cfg)
+		assert.Equal(t, Report{
+			"host":  SourceSystem,
+			"port":  base,
+			"debug": local,
+		}, report)
+	})
+
+	t.Run("missing optional files are skipped", func(t *testing.T) {
+		t.Parallel()
+		var cfg FilesConfig
+		report, err := LoadFiles([]File{
+			{Path: base},
+			{Path: filepath.Join(dir, ".env.missing"), Optional: true},
+			{Path: prod},
+		}, &cfg, WithIsolatedEnv())
+		require.NoError(t, err)
+
+		assert.Equal(t, "prod", cfg.Host)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, prod, report["host"])
+		assert.Equal(t, base, report["port"])
+	})
+
+	t.Run("missing required file", func(t *testing.T) {
+		t.Parallel()
+		var cfg FilesConfig
+		_, err := LoadFiles([]File{{Path: filepath.Join(dir, ".env.missing")}}, &cfg, WithIsolatedEnv())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read env file")
+	})
+}
+
+func TestStandardFiles(t *testing.T) {
+	assert.Equal(t, []File{
+		{Path: filepath.Join("cfg", ".env"), Optional: true},
+		{Path: filepath.Join("cfg", ".env.dev"), Optional: true},
+		{Path: filepath.Join("cfg", ".env.local"), Optional: true},
+	}, StandardFiles("cfg", consts.EnvDev))
+
+	assert.Len(t, StandardFiles("", ""), 2)
+}
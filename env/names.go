@@ -0,0 +1,74 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// readAutoNames sets the fields without an `env` tag from the variables
+// named after their path. It runs before the tagged fields are read, so that
+// `env-default` and `env-required` tags see the values it sets.
+func (o *options) readAutoNames(elem reflect.Value, lookup lookupFunc, report Report) error {
+	return reflectx.Walk(elem, func(f reflectx.Field) error {
+		if _, ok := f.StructField.Tag.Lookup("env"); ok {
+			return nil
+		}
+
+		name := o.autoName(f)
+		value, source, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+
+		if err := setValue(f, value); err != nil {
+			return fmt.Errorf("invalid value in environment variable %s: %w", name, err)
+		}
+		report[f.Key()] = source
+		return nil
+	})
+}
+
+// autoName derives the variable name of a field from its path.
+func (o *options) autoName(f reflectx.Field) string {
+	var segments []string
+	if o.prefix != "" {
+		segments = append(segments, o.prefix)
+	}
+	for _, sf := range append(append([]reflect.StructField{}, f.Parents...), f.StructField) {
+		if _, squash := reflectx.KeyName(sf); squash {
+			continue
+		}
+		segments = append(segments, strings.ToUpper(segment(sf)))
+	}
+	return strings.Join(segments, o.separator)
+}
+
+// segment returns the `mapstructure` name of a field, or its name in snake case.
+func segment(sf reflect.StructField) string {
+	if name, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ","); name != "" {
+		return name
+	}
+	return snakeCase(sf.Name)
+}
+
+// snakeCase converts a Go identifier to snake case, keeping acronyms together:
+// "UDPAddress" becomes "udp_address".
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
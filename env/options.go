@@ -0,0 +1,79 @@
+package env
+
+import (
+	"github.com/shanth1/gotools/internal/secrets"
+)
+
+// Resolver resolves secret references in variable values.
+// It is the same type as conf.Resolver, so the resolvers of conf can be used.
+type Resolver = secrets.Resolver
+
+type options struct {
+	lookup    func(name string) (string, bool)
+	secrets   bool
+	resolvers []Resolver
+	isolated  bool
+	autoNames bool
+	prefix    string
+	separator string
+}
+
+// option defines a function for configuring how variables are loaded.
+type option func(*options)
+
+func newOptions(opts ...option) *options {
+	o := &options{separator: "_"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAutoNames derives variable names for fields without an `env` tag from
+// their path in the struct, upper-cased and joined with the separator:
+// Log.Level becomes APP_LOG_LEVEL with prefix "APP" (LOG_LEVEL with an empty prefix).
+// Path segments are `mapstructure` tag names, or the field names in snake case.
+// Explicit `env` tags always win.
+func WithAutoNames(prefix string) option {
+	return func(o *options) {
+		o.autoNames = true
+		o.prefix = prefix
+	}
+}
+
+// WithSeparator sets the separator placed between the prefix and the path
+// segments of derived names. The default is "_".
+func WithSeparator(sep string) option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithIsolatedEnv reads the env file without writing its entries into the
+// process environment. The entries are only visible to this call, below the
+// system variables, so parallel tests and child processes are not affected.
+func WithIsolatedEnv() option {
+	return func(o *options) {
+		o.isolated = true
+	}
+}
+
+// WithLookup adds a function to read variables from, e.g. a map lookup in
+// tests. It is layered below the process environment, which cleanenv always
+// reads, and above the env file, which then does not modify the process environment.
+func WithLookup(lookup func(name string) (string, bool)) option {
+	return func(o *options) {
+		o.lookup = lookup
+	}
+}
+
+// WithSecrets resolves secret references (`file:///run/secrets/token`,
+// `${ENV:NAME}`, see conf.ResolveSecrets) in the loaded values.
+// The given resolvers are tried before the default ones; `${ENV:NAME}` uses
+// the same lookup as the variables. Without this option values are kept as they are.
+func WithSecrets(resolvers ...Resolver) option {
+	return func(o *options) {
+		o.secrets = true
+		o.resolvers = append(o.resolvers, resolvers...)
+	}
+}
@@ -0,0 +1,114 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// Variable describes an environment variable read into a config field.
+type Variable struct {
+	Name        string // Variable name; alternatives are comma-separated (e.g. "HOST, HOSTNAME")
+	Type        string // Go type of the field (e.g. "int", "time.Duration")
+	Default     string // `env-default` tag, or the shared `default` tag
+	Required    bool   // `env-required:"true"`
+	Description string // `env-description` tag, or the `usage` tag
+}
+
+// Variables lists the environment variables of a config struct (or pointer to one)
+// in field order, with names derived as LoadIntoStruct would with the same options.
+// Fields without a variable are skipped.
+func Variables(cfg interface{}, opts ...option) ([]Variable, error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct or a pointer to a struct, but got %T", cfg)
+	}
+
+	o := newOptions(opts...)
+	var vars []Variable
+	_ = reflectx.Walk(reflect.New(t).Elem(), func(f reflectx.Field) error {
+		names := o.varNames(f)
+		if len(names) == 0 {
+			return nil
+		}
+
+		def, ok := f.StructField.Tag.Lookup("env-default")
+		if !ok {
+			def = f.Tag("default")
+		}
+		desc := f.Tag("env-description")
+		if desc == "" {
+			desc = f.Tag("usage")
+		}
+
+		vars = append(vars, Variable{
+			Name:        strings.Join(names, ", "),
+			Type:        f.StructField.Type.String(),
+			Default:     def,
+			Required:    f.Tag("env-required") == "true",
+			Description: desc,
+		})
+		return nil
+	})
+	return vars, nil
+}
+
+// WriteUsage writes the variables of a config struct as an aligned text table,
+// for --help output.
+func WriteUsage(w io.Writer, cfg interface{}, opts ...option) error {
+	vars, err := Variables(cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, v := range vars {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Name, v.Type, v.Default, yesNo(v.Required), v.Description)
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the variables of a config struct as a Markdown table,
+// for READMEs.
+func WriteMarkdown(w io.Writer, cfg interface{}, opts ...option) error {
+	vars, err := Variables(cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("| Name | Type | Default | Required | Description |\n")
+	b.WriteString("|------|------|---------|----------|-------------|\n")
+	for _, v := range vars {
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s | %s |\n",
+			v.Name, v.Type, markdownCode(v.Default), yesNo(v.Required), markdownCell(v.Description))
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownCell(s) + "`"
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type usageConfig struct {
+	Host    string        `env:"HOST,HOSTNAME" env-required:"true" env-description:"Server host"`
+	Port    int           `env:"PORT" env-default:"8080" usage:"Server port"`
+	Timeout time.Duration `default:"5s" usage:"Request timeout"`
+	Mode    string        `env:"MODE" env-description:"One of a|b"`
+	Hidden  string
+}
+
+func TestVariables(t *testing.T) {
+	t.Parallel()
+
+	vars, err := Variables(&usageConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, []Variable{
+		{Name: "HOST, HOSTNAME", Type: "string", Required: true, Description: "Server host"},
+		{Name: "PORT", Type: "int", Default: "8080", Description: "Server port"},
+		{Name: "MODE", Type: "string", Description: "One of a|b"},
+	}, vars)
+
+	vars, err = Variables(usageConfig{}, WithAutoNames("APP"))
+	require.NoError(t, err)
+	require.Len(t, vars, 5)
+	assert.Equal(t, Variable{Name: "APP_TIMEOUT", Type: "time.Duration", Default: "5s", Description: "Request timeout"}, vars[2])
+	assert.Equal(t, "APP_HIDDEN", vars[4].Name)
+
+	_, err = Variables(42)
+	assert.EqualError(t, err, "expected a struct or a pointer to a struct, but got int")
+}
+
+func TestWriteUsage(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteUsage(&buf, usageConfig{}))
+	assert.Equal(t, ""+
+		"NAME            TYPE    DEFAULT  REQUIRED  DESCRIPTION\n"+
+		"HOST, HOSTNAME  string           yes       Server host\n"+
+		"PORT            int     8080     no        Server port\n"+
+		"MODE            string           no        One of a|b\n", buf.String())
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, usageConfig{}))
+	assert.Equal(t, ""+
+		"| Name | Type | Default | Required | Description |\n"+
+		"|------|------|---------|----------|-------------|\n"+
+		"| `HOST, HOSTNAME` | `string` |  | yes | Server host |\n"+
+		"| `PORT` | `int` | `8080` | no | Server port |\n"+
+		"| `MODE` | `string` |  | no | One of a\\|b |\n", buf.String())
+}
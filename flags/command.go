@@ -0,0 +1,156 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Command is a subcommand run by a Dispatcher.
+type Command struct {
+	Name   string      // Name used on the command line (e.g. "migrate")
+	Usage  string      // One-line description shown in the help output
+	Config interface{} // Optional pointer to a struct whose fields are registered as the command's flags
+	// Run is called with the arguments remaining after the flags,
+	// once Config has been populated (see ParseFlagSet) and its required flags checked.
+	Run func(ctx context.Context, args []string) error
+}
+
+// Dispatcher runs the Command selected by the first command-line argument.
+//
+// Each command has its own flag.FlagSet built from its Config struct, so
+// `prog help <command>` and `prog <command> -h` print the flags of that
+// command only.
+type Dispatcher struct {
+	name     string
+	commands []Command
+	output   io.Writer
+}
+
+// NewDispatcher creates a dispatcher for the program name with the given
+// commands, listed in the help output in that order.
+func NewDispatcher(name string, commands ...Command) *Dispatcher {
+	return &Dispatcher{name: name, commands: commands, output: os.Stderr}
+}
+
+// SetOutput sets the destination of help and error messages (os.Stderr by default).
+func (d *Dispatcher) SetOutput(w io.Writer) {
+	d.output = w
+}
+
+// Run parses args (typically os.Args[1:]) and runs the selected command.
+//
+// `help`, `-h` and `--help`, optionally followed by a command name, print the
+// help and return flag.ErrHelp. A missing or unknown command prints the help
+// and returns an error.
+func (d *Dispatcher) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		d.Usage()
+		return errors.New("missing command")
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			cmd, ok := d.lookup(args[1])
+			if !ok {
+				d.Usage()
+				return fmt.Errorf("unknown command %q", args[1])
+			}
+			fs, err := d.flagSet(cmd)
+			if err != nil {
+				return err
+			}
+			fs.Usage()
+		} else {
+			d.Usage()
+		}
+		return flag.ErrHelp
+	}
+
+	cmd, ok := d.lookup(name)
+	if !ok {
+		d.Usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	fs, err := d.flagSet(cmd)
+	if err != nil {
+		return err
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("command %s: %w", cmd.Name, err)
+	}
+	if cmd.Config != nil {
+		// Config was checked to be a pointer to a struct by RegisterFlagSet.
+		if _, err := applyEnv(fs, reflect.ValueOf(cmd.Config).Elem()); err != nil {
+			return fmt.Errorf("command %s: %w", cmd.Name, err)
+		}
+		if err := CheckRequiredFlagSet(fs, cmd.Config); err != nil {
+			fs.Usage()
+			return fmt.Errorf("command %s: %w", cmd.Name, err)
+		}
+	}
+	if cmd.Run == nil {
+		return nil
+	}
+	return cmd.Run(ctx, fs.Args())
+}
+
+// Usage prints the list of commands.
+func (d *Dispatcher) Usage() {
+	fmt.Fprintf(d.output, "Usage: %s <command> [flags] [args]\n\nCommands:\n", d.name)
+	tw := tabwriter.NewWriter(d.output, 0, 0, 3, ' ', 0)
+	for _, cmd := range d.commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.Name, cmd.Usage)
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(d.output, "\nRun '%s help <command>' for the flags of a command.\n", d.name)
+}
+
+func (d *Dispatcher) lookup(name string) (Command, bool) {
+	for _, cmd := range d.commands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// flagSet builds the flag set of a command from its Config struct.
+func (d *Dispatcher) flagSet(cmd Command) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(d.name+" "+cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(d.output)
+	fs.Usage = func() {
+		fmt.Fprintf(d.output, "Usage: %s %s [flags] [args]\n", d.name, cmd.Name)
+		if cmd.Usage != "" {
+			fmt.Fprintf(d.output, "\n%s\n", cmd.Usage)
+		}
+		if hasFlags(fs) {
+			fmt.Fprintln(d.output, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+
+	if cmd.Config != nil {
+		if err := RegisterFlagSet(fs, cmd.Config); err != nil {
+			return nil, fmt.Errorf("command %s: %w", cmd.Name, err)
+		}
+	}
+	return fs, nil
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
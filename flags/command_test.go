@@ -0,0 +1,134 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type migrateConfig struct {
+	DSN     string        `flag:"dsn" default:"postgres://localhost" usage:"Database DSN"`
+	Timeout time.Duration `flag:"timeout" default:"30s" usage:"Migration timeout"`
+	DryRun  bool          `flag:"dry-run" usage:"Print the migrations without applying them"`
+}
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, *migrateConfig, *[]string, *bytes.Buffer) {
+	t.Helper()
+	var (
+		cfg  migrateConfig
+		args []string
+		out  bytes.Buffer
+	)
+	d := NewDispatcher("admin",
+		Command{
+			Name:   "migrate",
+			Usage:  "Apply database migrations",
+			Config: &cfg,
+			Run: func(ctx context.Context, a []string) error {
+				args = a
+				return nil
+			},
+		},
+		Command{
+			Name:  "version",
+			Usage: "Print the version",
+			Run: func(ctx context.Context, a []string) error {
+				return errors.New("version failed")
+			},
+		},
+	)
+	d.SetOutput(&out)
+	return d, &cfg, &args, &out
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs the command with its flags", func(t *testing.T) {
+		t.Parallel()
+		d, cfg, args, _ := newTestDispatcher(t)
+
+		err := d.Run(context.Background(), []string{"migrate", "-dsn", "postgres://db", "-dry-run", "up", "2"})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://db", cfg.DSN)
+		assert.Equal(t, 30*time.Second, cfg.Timeout)
+		assert.True(t, cfg.DryRun)
+		assert.Equal(t, []string{"up", "2"}, *args)
+	})
+
+	t.Run("returns the command error", func(t *testing.T) {
+		t.Parallel()
+		d, _, _, _ := newTestDispatcher(t)
+		assert.EqualError(t, d.Run(context.Background(), []string{"version"}), "version failed")
+	})
+
+	t.Run("lists commands", func(t *testing.T) {
+		t.Parallel()
+		d, _, _, out := newTestDispatcher(t)
+
+		err := d.Run(context.Background(), []string{"help"})
+		assert.ErrorIs(t, err, flag.ErrHelp)
+		assert.Equal(t, ""+
+			"Usage: admin <command> [flags] [args]\n\n"+
+			"Commands:\n"+
+			"  migrate   Apply database migrations\n"+
+			"  version   Print the version\n\n"+
+			"Run 'admin help <command>' for the flags of a command.\n", out.String())
+	})
+
+	t.Run("prints command help", func(t *testing.T) {
+		t.Parallel()
+		for _, args := range [][]string{{"help", "migrate"}, {"migrate", "-h"}} {
+			d, _, _, out := newTestDispatcher(t)
+
+			err := d.Run(context.Background(), args)
+			assert.ErrorIs(t, err, flag.ErrHelp)
+			assert.Contains(t, out.String(), "Usage: admin migrate [flags] [args]\n\nApply database migrations\n\nFlags:\n")
+			assert.Contains(t, out.String(), "-dsn string")
+			assert.Contains(t, out.String(), `(default "postgres://localhost")`)
+			assert.NotContains(t, out.String(), "version")
+		}
+	})
+
+	t.Run("missing and unknown commands", func(t *testing.T) {
+		t.Parallel()
+		d, _, _, out := newTestDispatcher(t)
+
+		assert.EqualError(t, d.Run(context.Background(), nil), "missing command")
+		assert.EqualError(t, d.Run(context.Background(), []string{"deploy"}), `unknown command "deploy"`)
+		assert.EqualError(t, d.Run(context.Background(), []string{"help", "deploy"}), `unknown command "deploy"`)
+		assert.Contains(t, out.String(), "Commands:")
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		t.Parallel()
+		d, _, _, _ := newTestDispatcher(t)
+
+		err := d.Run(context.Background(), []string{"migrate", "-timeout", "soon"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "command migrate: invalid value")
+	})
+}
+
+func TestRegisterFlagSet(t *testing.T) {
+	t.Parallel()
+
+	var cfg TestFlagConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, RegisterFlagSet(fs, &cfg))
+	require.NoError(t, fs.Parse([]string{"-host", "remote", "-retries", "3"}))
+
+	assert.Equal(t, "remote", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, int64(3), cfg.Retries)
+	assert.True(t, cfg.Enabled)
+	assert.Nil(t, flag.Lookup("retries"), "the global flag set must not be modified")
+
+	assert.EqualError(t, RegisterFlagSet(fs, cfg), "expected a pointer to a struct, but got flags.TestFlagConfig")
+}
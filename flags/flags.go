@@ -1,10 +1,15 @@
 package flags
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shanth1/gotools/internal/reflectx"
 )
 
 // RegisterFromStruct registers command-line flags based on a struct's fields and tags.
@@ -18,7 +23,20 @@ import (
 // - `default`: (optional) The default value for this flag.
 // - `usage`: (optional) The help text that will be shown when called with -h or -help.
 //
+// - `short`: (optional) A one-letter alias of the flag (e.g. -v for -verbose).
+// - `env`: (optional) Environment variable used when the flag is not given, applied by Parse.
+// - `required`: (optional) "true" if the flag must be given, checked by CheckRequired and Parse.
+//
 // Example: `flag:"level" default:"info" usage:"Logging level (debug, info, error)"`
+//
+// Supported types are strings, booleans, integers, unsigned integers, floats,
+// time.Duration, types implementing encoding.TextUnmarshaler (such as log.Level),
+// pointers to these, slices (repeated flags or comma-separated values) and
+// maps (key=value pairs, repeated or comma-separated).
+//
+// Fields of a nested struct are registered with the `flag` tag of the struct
+// field as a prefix: `flag:"db"` on the struct and `flag:"host"` on its field
+// give -db.host. Nested structs without a `flag` tag add no prefix.
 func RegisterFromStruct(cfgPtr interface{}) error {
 	val := reflect.ValueOf(cfgPtr)
 
@@ -26,45 +44,215 @@ func RegisterFromStruct(cfgPtr interface{}) error {
 		return fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
 	}
 
-	elem := val.Elem()
-	t := elem.Type()
+	return register(flag.CommandLine, val.Elem())
+}
+
+// RegisterFlagSet is like RegisterFromStruct but registers the flags on fs
+// instead of flag.CommandLine, for tests and subcommands.
+// fs.Parse must be called to populate the struct.
+func RegisterFlagSet(fs *flag.FlagSet, cfgPtr interface{}) error {
+	val := reflect.ValueOf(cfgPtr)
+
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, but got %T", cfgPtr)
+	}
+
+	return register(fs, val.Elem())
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		fieldVal := elem.Field(i)
+// register registers the flags of the struct fields on fs. Invalid `default`
+// values do not stop the registration: they are reported together, one per field.
+func register(fs *flag.FlagSet, elem reflect.Value) error {
+	var defaultErrs []error
+	err := reflectx.WalkAll(elem, func(f reflectx.Field) error {
+		field := f.StructField
+		fieldVal := f.Value
 
-		flagName := field.Tag.Get("flag")
+		flagName := f.FlagName()
 		if flagName == "" {
-			continue
+			return nil
 		}
 
 		defaultValue := field.Tag.Get("default")
 		usage := field.Tag.Get("usage")
+		var notes []string
+		if env := field.Tag.Get("env"); env != "" {
+			notes = append(notes, "env "+env)
+		}
+		if field.Tag.Get("required") == "true" {
+			notes = append(notes, "required")
+		}
+		if len(notes) > 0 {
+			usage = strings.TrimSpace(usage + " (" + strings.Join(notes, ", ") + ")")
+		}
 
 		if !fieldVal.CanSet() {
-			continue
-		}
-
-		switch field.Type.Kind() {
-		case reflect.String:
-			ptr := fieldVal.Addr().Interface().(*string)
-			flag.StringVar(ptr, flagName, defaultValue, usage)
-		case reflect.Int64:
-			defaultValInt, _ := strconv.ParseInt(defaultValue, 10, 64)
-			ptr := fieldVal.Addr().Interface().(*int64)
-			flag.Int64Var(ptr, flagName, defaultValInt, usage)
-		case reflect.Int:
-			defaultValInt, _ := strconv.Atoi(defaultValue)
-			ptr := fieldVal.Addr().Interface().(*int)
-			flag.IntVar(ptr, flagName, defaultValInt, usage)
-		case reflect.Bool:
-			defaultValBool, _ := strconv.ParseBool(defaultValue)
-			ptr := fieldVal.Addr().Interface().(*bool)
-			flag.BoolVar(ptr, flagName, defaultValBool, usage)
-		default:
-			return fmt.Errorf("unsupported type for flag registration: %s", field.Type.Kind())
+			return nil
+		}
+
+		if err := registerField(fs, field.Type, fieldVal, flagName, defaultValue, usage); err != nil {
+			if errors.Is(err, errUnsupported) {
+				return err
+			}
+			defaultErrs = append(defaultErrs,
+				fmt.Errorf("invalid default value %q for field %s (-%s): %w", defaultValue, fieldPath(f), flagName, err))
+			return nil
+		}
+
+		if short := field.Tag.Get("short"); short != "" {
+			fs.Var(fs.Lookup(flagName).Value, short, "Short for -"+flagName)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(defaultErrs...)
+}
+
+// errUnsupported is returned by registerField for types that cannot be parsed from flags.
+var errUnsupported = errors.New("unsupported type for flag registration")
+
+// registerField registers a single flag. Errors other than errUnsupported
+// come from parsing the default value.
+func registerField(fs *flag.FlagSet, t reflect.Type, fieldVal reflect.Value, flagName, defaultValue, usage string) error {
+	switch {
+	case reflectx.IsDuration(t):
+		defaultValDuration, err := parseDefault(defaultValue, time.ParseDuration)
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*time.Duration)
+		fs.DurationVar(ptr, flagName, defaultValDuration, usage)
+		return nil
+	case reflectx.IsTextUnmarshaler(t):
+		return registerValue(fs, fieldVal, flagName, defaultValue, usage)
+	case t.PkgPath() != "":
+		// Named types (e.g. type Mode string) cannot use the typed flag.XxxVar functions.
+		if !supported(t) {
+			return fmt.Errorf("%w: %s", errUnsupported, t)
 		}
+		return registerValue(fs, fieldVal, flagName, defaultValue, usage)
 	}
 
+	switch t.Kind() {
+	case reflect.String:
+		ptr := fieldVal.Addr().Interface().(*string)
+		fs.StringVar(ptr, flagName, defaultValue, usage)
+	case reflect.Int64:
+		defaultValInt, err := parseDefault(defaultValue, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		})
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*int64)
+		fs.Int64Var(ptr, flagName, defaultValInt, usage)
+	case reflect.Int:
+		defaultValInt, err := parseDefault(defaultValue, strconv.Atoi)
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*int)
+		fs.IntVar(ptr, flagName, defaultValInt, usage)
+	case reflect.Bool:
+		defaultValBool, err := parseDefault(defaultValue, strconv.ParseBool)
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*bool)
+		fs.BoolVar(ptr, flagName, defaultValBool, usage)
+	case reflect.Uint:
+		defaultValUint, err := parseDefault(defaultValue, func(s string) (uint64, error) {
+			return strconv.ParseUint(s, 10, strconv.IntSize)
+		})
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*uint)
+		fs.UintVar(ptr, flagName, uint(defaultValUint), usage)
+	case reflect.Uint64:
+		defaultValUint, err := parseDefault(defaultValue, func(s string) (uint64, error) {
+			return strconv.ParseUint(s, 10, 64)
+		})
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*uint64)
+		fs.Uint64Var(ptr, flagName, defaultValUint, usage)
+	case reflect.Float64:
+		defaultValFloat, err := parseDefault(defaultValue, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*float64)
+		fs.Float64Var(ptr, flagName, defaultValFloat, usage)
+	default:
+		if !supported(t) {
+			return fmt.Errorf("%w: %s", errUnsupported, t)
+		}
+		return registerValue(fs, fieldVal, flagName, defaultValue, usage)
+	}
+	return nil
+}
+
+// registerValue sets the default value on the field and registers it as a generic flag.
+func registerValue(fs *flag.FlagSet, fieldVal reflect.Value, flagName, defaultValue, usage string) error {
+	if defaultValue != "" {
+		if err := reflectx.SetString(fieldVal, defaultValue); err != nil {
+			return err
+		}
+	}
+	fs.Var(&value{v: fieldVal}, flagName, usage)
 	return nil
 }
+
+func parseDefault[T any](s string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	if s == "" {
+		return zero, nil
+	}
+	return parse(s)
+}
+
+// fieldPath returns the Go path of a field (e.g. "DB.Timeout") for error messages.
+func fieldPath(f reflectx.Field) string {
+	var b strings.Builder
+	for _, p := range f.Parents {
+		b.WriteString(p.Name + ".")
+	}
+	return b.String() + f.StructField.Name
+}
+
+// supported reports whether values of type t can be parsed from flags.
+func supported(t reflect.Type) bool {
+	if reflectx.IsDuration(t) || reflectx.IsTextUnmarshaler(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr:
+		return supported(t.Elem())
+	case reflect.Slice:
+		return isScalar(t.Elem())
+	case reflect.Map:
+		return isScalar(t.Key()) && isScalar(t.Elem())
+	default:
+		return false
+	}
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Ptr:
+		return reflectx.IsTextUnmarshaler(t)
+	default:
+		return supported(t)
+	}
+}
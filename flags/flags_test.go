@@ -3,11 +3,16 @@ package flags
 import (
 	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/shanth1/gotools/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,12 +71,12 @@ func TestRegisterFromStruct_Subprocess(t *testing.T) {
 func TestRegisterFromStruct_Unit(t *testing.T) {
 	t.Run("unsupported type", func(t *testing.T) {
 		type UnsupportedConfig struct {
-			Value float64 `flag:"value"`
+			Value complex128 `flag:"value"`
 		}
 		var cfg UnsupportedConfig
 		err := RegisterFromStruct(&cfg)
 		require.Error(t, err)
-		assert.EqualError(t, err, "unsupported type for flag registration: float64")
+		assert.EqualError(t, err, "unsupported type for flag registration: complex128")
 	})
 
 	t.Run("target is not a pointer", func(t *testing.T) {
@@ -81,3 +86,131 @@ func TestRegisterFromStruct_Unit(t *testing.T) {
 		assert.EqualError(t, err, "expected a pointer to a struct, but got flags.TestFlagConfig")
 	})
 }
+
+type richDB struct {
+	Host    string        `flag:"host" default:"localhost"`
+	Timeout time.Duration `flag:"timeout" default:"5s"`
+}
+
+type richConfig struct {
+	Ratio   float64           `flag:"ratio" default:"0.5"`
+	Weight  float32           `flag:"weight"`
+	Workers uint              `flag:"workers" default:"4"`
+	Limit   uint64            `flag:"limit"`
+	Small   uint8             `flag:"small"`
+	Tags    []string          `flag:"tag" default:"a,b"`
+	Ports   []int             `flag:"port"`
+	Labels  map[string]string `flag:"label"`
+	Level   log.Level         `flag:"level" default:"warn"`
+	IP      net.IP            `flag:"ip"`
+	Mode    mode              `flag:"mode" default:"fast"`
+	Ptr     *int              `flag:"ptr"`
+	DB      richDB            `flag:"db"`
+	Plain   struct {
+		Name string `flag:"name"`
+	}
+}
+
+type mode string
+
+func TestRegister_RichTypes(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var cfg richConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, register(fs, reflect.ValueOf(&cfg).Elem()))
+		require.NoError(t, fs.Parse(nil))
+
+		assert.Equal(t, 0.5, cfg.Ratio)
+		assert.Equal(t, uint(4), cfg.Workers)
+		assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+		assert.Equal(t, log.LevelWarn, cfg.Level)
+		assert.Equal(t, mode("fast"), cfg.Mode)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, 5*time.Second, cfg.DB.Timeout)
+		assert.Equal(t, "warn", fs.Lookup("level").DefValue)
+		assert.Equal(t, "a,b", fs.Lookup("tag").DefValue)
+	})
+
+	t.Run("parses command line", func(t *testing.T) {
+		var cfg richConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, register(fs, reflect.ValueOf(&cfg).Elem()))
+		require.NoError(t, fs.Parse([]string{
+			"-ratio", "1.25", "-weight", "2.5", "-workers", "8", "-limit", "1000", "-small", "7",
+			"-tag", "x", "-tag", "y,z",
+			"-port", "80", "-port", "443",
+			"-label", "env=prod", "-label", "region=eu,zone=a",
+			"-level", "debug", "-ip", "10.0.0.1", "-mode", "slow", "-ptr", "3",
+			"-db.host", "db.local", "-db.timeout", "1m", "-name", "plain",
+		}))
+
+		assert.Equal(t, 1.25, cfg.Ratio)
+		assert.Equal(t, float32(2.5), cfg.Weight)
+		assert.Equal(t, uint(8), cfg.Workers)
+		assert.Equal(t, uint64(1000), cfg.Limit)
+		assert.Equal(t, uint8(7), cfg.Small)
+		assert.Equal(t, []string{"x", "y", "z"}, cfg.Tags, "the first flag replaces the default")
+		assert.Equal(t, []int{80, 443}, cfg.Ports)
+		assert.Equal(t, map[string]string{"env": "prod", "region": "eu", "zone": "a"}, cfg.Labels)
+		assert.Equal(t, log.LevelDebug, cfg.Level)
+		assert.Equal(t, net.ParseIP("10.0.0.1"), cfg.IP)
+		assert.Equal(t, mode("slow"), cfg.Mode)
+		require.NotNil(t, cfg.Ptr)
+		assert.Equal(t, 3, *cfg.Ptr)
+		assert.Equal(t, "db.local", cfg.DB.Host)
+		assert.Equal(t, time.Minute, cfg.DB.Timeout)
+		assert.Equal(t, "plain", cfg.Plain.Name)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		var cfg richConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		require.NoError(t, register(fs, reflect.ValueOf(&cfg).Elem()))
+
+		err := fs.Parse([]string{"-level", "loud"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown log level")
+	})
+
+	t.Run("invalid defaults", func(t *testing.T) {
+		type BadDB struct {
+			Timeout time.Duration `flag:"timeout" default:"soon"`
+		}
+		type BadDefault struct {
+			Enabled bool      `flag:"enabled" default:"tru"`
+			Port    int       `flag:"port" default:"80a"`
+			Host    string    `flag:"host" default:"localhost"`
+			Level   log.Level `flag:"level" default:"loud"`
+			DB      BadDB     `flag:"db"`
+		}
+		var cfg BadDefault
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		err := register(fs, reflect.ValueOf(&cfg).Elem())
+		require.Error(t, err)
+
+		msg := err.Error()
+		assert.Contains(t, msg, `invalid default value "tru" for field Enabled (-enabled)`)
+		assert.Contains(t, msg, `invalid default value "80a" for field Port (-port)`)
+		assert.Contains(t, msg, `invalid default value "loud" for field Level (-level)`)
+		assert.Contains(t, msg, `invalid default value "soon" for field DB.Timeout (-db.timeout)`)
+		assert.NotContains(t, msg, "Host")
+		assert.Equal(t, "localhost", cfg.Host, "valid flags are still registered")
+	})
+	t.Run("fields ignored by mapstructure", func(t *testing.T) {
+		type Nested struct {
+			Name string `flag:"name"`
+		}
+		type FlagOnly struct {
+			Debug  bool   `mapstructure:"-" flag:"debug"`
+			Nested Nested `mapstructure:"-"`
+		}
+		var cfg FlagOnly
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, register(fs, reflect.ValueOf(&cfg).Elem()))
+		require.NoError(t, fs.Parse([]string{"-debug", "-name", "cli"}))
+
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, "cli", cfg.Nested.Name)
+	})
+}
@@ -0,0 +1,149 @@
+package flags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// Source tells where the value of a flag came from.
+type Source string
+
+const (
+	SourceCLI     Source = "cli"     // Given on the command line, by name or short alias
+	SourceEnv     Source = "env"     // Read from the variable of the `env` tag
+	SourceDefault Source = "default" // Neither given nor set in the environment
+)
+
+// Sources maps flag names (e.g. "db.host") to the source of their value.
+type Sources map[string]Source
+
+// Parse parses the command line into the struct registered with RegisterFromStruct,
+// like flag.Parse, then completes it with ParseFlagSet's env fallback and required checks.
+func Parse(cfgPtr interface{}) (Sources, error) {
+	return ParseFlagSet(flag.CommandLine, cfgPtr, os.Args[1:])
+}
+
+// ParseFlagSet parses args into the struct registered on fs with RegisterFlagSet.
+// Flags that were not given take the value of the environment variable of their
+// `env` tag, if it is set. Flags tagged `required:"true"` must be given or set
+// in the environment. The returned Sources tell where every value came from.
+func ParseFlagSet(fs *flag.FlagSet, cfgPtr interface{}, args []string) (Sources, error) {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	sources, err := applyEnv(fs, elem)
+	if err != nil {
+		return nil, err
+	}
+	return sources, checkRequired(elem, sources)
+}
+
+// CheckRequired reports the flags tagged `required:"true"` that were neither
+// given on the command line nor set through their `env` variable.
+// Call it after flag.Parse():
+//
+//	Token string `flag:"token" required:"true" usage:"API token"`
+//
+// A default value does not satisfy the requirement: the flag must be given explicitly.
+func CheckRequired(cfgPtr interface{}) error {
+	return CheckRequiredFlagSet(flag.CommandLine, cfgPtr)
+}
+
+// CheckRequiredFlagSet is like CheckRequired for flags registered on fs with RegisterFlagSet.
+func CheckRequiredFlagSet(fs *flag.FlagSet, cfgPtr interface{}) error {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return err
+	}
+	return checkRequired(elem, sources(fs, elem))
+}
+
+// sources determines the source of every flag without modifying the struct.
+func sources(fs *flag.FlagSet, elem reflect.Value) Sources {
+	visited := visitedFlags(fs)
+	result := Sources{}
+	_ = reflectx.WalkAll(elem, func(f reflectx.Field) error {
+		flagName := f.FlagName()
+		if flagName == "" {
+			return nil
+		}
+		result[flagName] = SourceDefault
+		if visited[flagName] || visited[f.StructField.Tag.Get("short")] {
+			result[flagName] = SourceCLI
+		} else if _, _, ok := lookupEnv(f); ok {
+			result[flagName] = SourceEnv
+		}
+		return nil
+	})
+	return result
+}
+
+// applyEnv sets the flags that were not given from their environment variables.
+// The flags are set through their flag.Value so that they still count as not
+// visited, and a later call sees the same sources.
+func applyEnv(fs *flag.FlagSet, elem reflect.Value) (Sources, error) {
+	result := sources(fs, elem)
+	err := reflectx.WalkAll(elem, func(f reflectx.Field) error {
+		flagName := f.FlagName()
+		if result[flagName] != SourceEnv {
+			return nil
+		}
+		env, value, _ := lookupEnv(f)
+		fl := fs.Lookup(flagName)
+		if fl == nil {
+			return nil
+		}
+		if err := fl.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value %q for environment variable %s (-%s): %w", value, env, flagName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lookupEnv returns the first variable set among the comma-separated names
+// of the `env` tag of a field (e.g. `env:"APP_VERBOSE,VERBOSE"`) and its value.
+func lookupEnv(f reflectx.Field) (name, value string, ok bool) {
+	for _, name := range f.EnvNames() {
+		if value, ok := os.LookupEnv(name); ok {
+			return name, value, true
+		}
+	}
+	return "", "", false
+}
+
+func checkRequired(elem reflect.Value, sources Sources) error {
+	var missing []string
+	_ = reflectx.WalkAll(elem, func(f reflectx.Field) error {
+		flagName := f.FlagName()
+		if flagName != "" && f.StructField.Tag.Get("required") == "true" && sources[flagName] == SourceDefault {
+			missing = append(missing, "-"+flagName)
+		}
+		return nil
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func visitedFlags(fs *flag.FlagSet) map[string]bool {
+	visited := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+	return visited
+}
@@ -0,0 +1,166 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requiredConfig struct {
+	Token string `flag:"token" required:"true" usage:"API token"`
+	Mode  string `flag:"mode" default:"fast" required:"true"`
+	Host  string `flag:"host"`
+	DB    struct {
+		DSN string `flag:"dsn" required:"true"`
+	} `flag:"db"`
+}
+
+func TestCheckRequiredFlagSet(t *testing.T) {
+	t.Parallel()
+
+	parse := func(t *testing.T, args ...string) (*flag.FlagSet, *requiredConfig) {
+		t.Helper()
+		var cfg requiredConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlagSet(fs, &cfg))
+		require.NoError(t, fs.Parse(args))
+		return fs, &cfg
+	}
+
+	t.Run("all required flags set", func(t *testing.T) {
+		t.Parallel()
+		fs, cfg := parse(t, "-token", "abc", "-mode", "fast", "-db.dsn", "postgres://db")
+		assert.NoError(t, CheckRequiredFlagSet(fs, cfg))
+	})
+
+	t.Run("reports every missing flag", func(t *testing.T) {
+		t.Parallel()
+		fs, cfg := parse(t, "-host", "local")
+		err := CheckRequiredFlagSet(fs, cfg)
+		assert.EqualError(t, err, "missing required flags: -token, -mode, -db.dsn")
+	})
+
+	t.Run("usage marks required flags", func(t *testing.T) {
+		t.Parallel()
+		fs, _ := parse(t)
+		assert.Equal(t, "API token (required)", fs.Lookup("token").Usage)
+		assert.Equal(t, "(required)", fs.Lookup("db.dsn").Usage)
+	})
+
+	t.Run("dispatcher checks required flags", func(t *testing.T) {
+		t.Parallel()
+		var cfg requiredConfig
+		ran := false
+		d := NewDispatcher("tool", Command{
+			Name:   "run",
+			Config: &cfg,
+			Run: func(ctx context.Context, args []string) error {
+				ran = true
+				return nil
+			},
+		})
+		var out bytes.Buffer
+		d.SetOutput(&out)
+
+		err := d.Run(context.Background(), []string{"run", "-token", "abc"})
+		assert.EqualError(t, err, "command run: missing required flags: -mode, -db.dsn")
+		assert.False(t, ran)
+		assert.Contains(t, out.String(), "Usage: tool run [flags] [args]", "the command help should be printed")
+	})
+
+	t.Run("target is not a pointer", func(t *testing.T) {
+		t.Parallel()
+		err := CheckRequiredFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), requiredConfig{})
+		assert.EqualError(t, err, "expected a pointer to a struct, but got flags.requiredConfig")
+	})
+}
+
+type sourceConfig struct {
+	Verbose bool          `flag:"verbose" short:"v" env:"TEST_FLAGS_VERBOSE" usage:"Verbose output"`
+	Level   string        `flag:"level" short:"l" env:"TEST_FLAGS_LEVEL" default:"info"`
+	Timeout time.Duration `flag:"timeout" env:"TEST_FLAGS_TIMEOUT" default:"5s"`
+	Tags    []string      `flag:"tag" env:"TEST_FLAGS_TAGS"`
+	Token   string        `flag:"token" env:"TEST_FLAGS_TOKEN,TEST_FLAGS_API_TOKEN" required:"true"`
+}
+
+func TestParseFlagSet(t *testing.T) {
+	newFlagSet := func(t *testing.T) (*flag.FlagSet, *sourceConfig) {
+		t.Helper()
+		var cfg sourceConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		require.NoError(t, RegisterFlagSet(fs, &cfg))
+		return fs, &cfg
+	}
+
+	t.Run("short aliases share the value", func(t *testing.T) {
+		fs, cfg := newFlagSet(t)
+		sources, err := ParseFlagSet(fs, cfg, []string{"-v", "-l", "debug", "-token", "abc"})
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Verbose)
+		assert.Equal(t, "debug", cfg.Level)
+		assert.Equal(t, Sources{
+			"verbose": SourceCLI,
+			"level":   SourceCLI,
+			"timeout": SourceDefault,
+			"tag":     SourceDefault,
+			"token":   SourceCLI,
+		}, sources)
+		assert.Equal(t, "Verbose output (env TEST_FLAGS_VERBOSE)", fs.Lookup("verbose").Usage)
+		assert.Equal(t, "Short for -verbose", fs.Lookup("v").Usage)
+	})
+
+	t.Run("env fallback", func(t *testing.T) {
+		t.Setenv("TEST_FLAGS_VERBOSE", "true")
+		t.Setenv("TEST_FLAGS_LEVEL", "warn")
+		t.Setenv("TEST_FLAGS_TIMEOUT", "1m")
+		t.Setenv("TEST_FLAGS_TAGS", "a,b")
+		t.Setenv("TEST_FLAGS_TOKEN", "from-env")
+
+		fs, cfg := newFlagSet(t)
+		sources, err := ParseFlagSet(fs, cfg, []string{"-level", "error"})
+		require.NoError(t, err)
+
+		assert.Equal(t, sourceConfig{Verbose: true, Level: "error", Timeout: time.Minute, Tags: []string{"a", "b"}, Token: "from-env"}, *cfg)
+		assert.Equal(t, Sources{
+			"verbose": SourceEnv,
+			"level":   SourceCLI,
+			"timeout": SourceEnv,
+			"tag":     SourceEnv,
+			"token":   SourceEnv,
+		}, sources)
+		assert.NoError(t, CheckRequiredFlagSet(fs, cfg), "a required flag may come from the environment")
+	})
+
+	t.Run("env fallback to a later name", func(t *testing.T) {
+		t.Setenv("TEST_FLAGS_API_TOKEN", "from-alias")
+
+		fs, cfg := newFlagSet(t)
+		sources, err := ParseFlagSet(fs, cfg, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "from-alias", cfg.Token)
+		assert.Equal(t, SourceEnv, sources["token"])
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		t.Setenv("TEST_FLAGS_TIMEOUT", "soon")
+
+		fs, cfg := newFlagSet(t)
+		_, err := ParseFlagSet(fs, cfg, []string{"-token", "abc"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "soon" for environment variable TEST_FLAGS_TIMEOUT (-timeout)`)
+	})
+
+	t.Run("missing required flag", func(t *testing.T) {
+		fs, cfg := newFlagSet(t)
+		_, err := ParseFlagSet(fs, cfg, nil)
+		assert.EqualError(t, err, "missing required flags: -token")
+	})
+}
@@ -0,0 +1,92 @@
+package flags
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// value adapts a struct field to the flag.Value interface.
+// Slices and maps accumulate repeated flags; the first flag given on the
+// command line replaces the default.
+type value struct {
+	v   reflect.Value
+	set bool
+}
+
+func (f *value) String() string {
+	if f == nil || !f.v.IsValid() {
+		return ""
+	}
+	return formatValue(f.v)
+}
+
+func (f *value) Set(s string) error {
+	t := f.v.Type()
+	if (t.Kind() != reflect.Slice && t.Kind() != reflect.Map) || reflectx.IsTextUnmarshaler(t) {
+		f.set = true
+		return reflectx.SetString(f.v, s)
+	}
+
+	parsed := reflect.New(t).Elem()
+	if err := reflectx.SetString(parsed, s); err != nil {
+		return err
+	}
+	switch {
+	case !f.set || f.v.IsNil():
+		f.v.Set(parsed)
+	case t.Kind() == reflect.Slice:
+		f.v.Set(reflect.AppendSlice(f.v, parsed))
+	default:
+		iter := parsed.MapRange()
+		for iter.Next() {
+			f.v.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	f.set = true
+	return nil
+}
+
+func (f *value) IsBoolFlag() bool {
+	return f.v.IsValid() && f.v.Kind() == reflect.Bool
+}
+
+// formatValue renders a field value the way it is written on the command line.
+func formatValue(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return ""
+		}
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			parts = append(parts, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,25 @@
+package reflectx
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ApplyDefaults sets the `default` tag value of the zero-valued leaf fields
+// of the struct value elem whose key is not set by a higher layer, calling
+// onApply (if not nil) with the key of each field set.
+func ApplyDefaults(elem reflect.Value, isSet func(key string) bool, onApply func(key string)) error {
+	return Walk(elem, func(f Field) error {
+		defaultValue, ok := f.StructField.Tag.Lookup("default")
+		if !ok || isSet(f.Key()) || !f.Value.IsZero() {
+			return nil
+		}
+		if err := SetString(f.Value, defaultValue); err != nil {
+			return fmt.Errorf("invalid default value for field %s: %w", f.Key(), err)
+		}
+		if onApply != nil {
+			onApply(f.Key())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,337 @@
+// Package reflectx contains the reflection helpers shared by the configuration
+// packages (conf, env, flags): walking config structs and parsing string values
+// taken from tags, environment variables and command-line flags.
+package reflectx
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Field describes a leaf field reached while walking a config struct.
+type Field struct {
+	// Value is the settable field value.
+	Value reflect.Value
+	// StructField is the reflected field definition, including its tags.
+	StructField reflect.StructField
+	// Parents holds the enclosing struct fields, outermost first.
+	Parents []reflect.StructField
+	// Keys is the mapstructure key path of the field (e.g. ["log", "level"]).
+	Keys []string
+}
+
+// Key returns the dotted mapstructure key of the field (e.g. "log.level").
+func (f Field) Key() string {
+	return strings.Join(f.Keys, ".")
+}
+
+// Tag returns the value of the given tag on the field.
+func (f Field) Tag(name string) string {
+	return f.StructField.Tag.Get(name)
+}
+
+// FlagName returns the command-line flag of the field: its `flag` tag prefixed
+// by the `flag` tags of its parents (e.g. "db.host"), or "" if it has none.
+func (f Field) FlagName() string {
+	name := f.Tag("flag")
+	if name == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, p := range f.Parents {
+		if prefix := p.Tag.Get("flag"); prefix != "" {
+			b.WriteString(prefix + ".")
+		}
+	}
+	return b.String() + name
+}
+
+// EnvNames returns the environment variables of the field in order of
+// precedence: the comma-separated names of its `env` tag, each prefixed by
+// the `env-prefix` tags of its parents. It returns nil if the field has none.
+func (f Field) EnvNames() []string {
+	tag := f.Tag("env")
+	if tag == "" {
+		return nil
+	}
+	var prefix string
+	for _, p := range f.Parents {
+		prefix += p.Tag.Get("env-prefix")
+	}
+	var names []string
+	for _, name := range strings.Split(tag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, prefix+name)
+		}
+	}
+	return names
+}
+
+// ExpectStructPtr returns the struct value behind ptr or an error
+// if ptr is not a pointer to a struct.
+func ExpectStructPtr(ptr interface{}) (reflect.Value, error) {
+	val := reflect.ValueOf(ptr)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a pointer to a struct, but got %T", ptr)
+	}
+	return val.Elem(), nil
+}
+
+// IsDuration reports whether t is time.Duration.
+func IsDuration(t reflect.Type) bool {
+	return t == durationType
+}
+
+// IsTextUnmarshaler reports whether pointers to t implement encoding.TextUnmarshaler.
+func IsTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// IsLeaf reports whether values of type t are treated as a single value
+// rather than a struct to descend into.
+func IsLeaf(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	return t == timeType || IsTextUnmarshaler(t)
+}
+
+// Walk calls fn for every exported leaf field of the struct value v,
+// descending into nested structs. Keys follow mapstructure conventions:
+// the `mapstructure` tag name, or the lower-cased field name if absent,
+// `mapstructure:"-"` skips the field and `,squash` flattens an embedded struct.
+func Walk(v reflect.Value, fn func(f Field) error) error {
+	return walk(v, nil, nil, true, fn)
+}
+
+// WalkAll is like Walk but does not skip fields tagged `mapstructure:"-"`,
+// for sources that do not decode through mapstructure (e.g. flags).
+// The keys of such fields use the lower-cased field name.
+func WalkAll(v reflect.Value, fn func(f Field) error) error {
+	return walk(v, nil, nil, false, fn)
+}
+
+// WalkValues is like Walk but also descends into the struct values held by
+// leaf fields: non-nil pointers to structs and the elements of slices of
+// structs, whose keys are indexed (e.g. "items[0].name"). The pointer and
+// slice fields themselves are passed to fn before their content.
+func WalkValues(v reflect.Value, fn func(f Field) error) error {
+	return walk(v, nil, nil, true, func(f Field) error {
+		if err := fn(f); err != nil {
+			return err
+		}
+		return walkContent(f, fn)
+	})
+}
+
+// walkContent walks the structs held by a leaf field for WalkValues.
+func walkContent(f Field, fn func(f Field) error) error {
+	parents := append(append([]reflect.StructField{}, f.Parents...), f.StructField)
+	walkElem := func(elem reflect.Value, keys []string) error {
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct || IsLeaf(elem.Type()) {
+			return nil
+		}
+		return walk(elem, parents, keys, true, func(inner Field) error {
+			if err := fn(inner); err != nil {
+				return err
+			}
+			return walkContent(inner, fn)
+		})
+	}
+
+	switch f.Value.Kind() {
+	case reflect.Ptr:
+		return walkElem(f.Value, f.Keys)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < f.Value.Len(); i++ {
+			keys := append([]string{}, f.Keys...)
+			if len(keys) > 0 {
+				keys[len(keys)-1] += "[" + strconv.Itoa(i) + "]"
+			}
+			if err := walkElem(f.Value.Index(i), keys); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walk(v reflect.Value, parents []reflect.StructField, keys []string, skipIgnored bool, fn func(f Field) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !IsVisible(sf) {
+			continue
+		}
+
+		name, squash := KeyName(sf)
+		if name == "-" {
+			if skipIgnored {
+				continue
+			}
+			name = strings.ToLower(sf.Name)
+		}
+
+		fieldVal := v.Field(i)
+		fieldKeys := keys
+		if !squash {
+			fieldKeys = append(append([]string{}, keys...), name)
+		}
+
+		if !IsLeaf(sf.Type) {
+			if err := walk(fieldVal, append(append([]reflect.StructField{}, parents...), sf), fieldKeys, skipIgnored, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(Field{Value: fieldVal, StructField: sf, Parents: parents, Keys: fieldKeys}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsVisible reports whether a struct field takes part in decoding:
+// exported fields and embedded structs, whose exported fields are promoted.
+func IsVisible(sf reflect.StructField) bool {
+	return sf.IsExported() || (sf.Anonymous && !IsLeaf(sf.Type))
+}
+
+// KeyName returns the mapstructure key of a struct field and whether
+// the field is an embedded struct squashed into its parent.
+func KeyName(sf reflect.StructField) (name string, squash bool) {
+	tag := sf.Tag.Get("mapstructure")
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, squash && sf.Type.Kind() == reflect.Struct
+}
+
+// SetString parses s according to the type of v and stores the result in v.
+//
+// Supported types are strings, booleans, integers, unsigned integers, floats,
+// time.Duration, types implementing encoding.TextUnmarshaler, pointers to
+// any of these, slices (comma-separated elements) and maps (comma-separated
+// key=value pairs).
+func SetString(v reflect.Value, s string) error {
+	return SetStringSep(v, s, ",")
+}
+
+// SetStringSep is like SetString but splits slice and map values on sep.
+func SetStringSep(v reflect.Value, s, sep string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := SetStringSep(elem.Elem(), s, sep); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		parts := splitList(s, sep)
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := SetStringSep(slice.Index(i), part, sep); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range splitList(s, sep) {
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q, expected key=value", pair)
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := SetStringSep(key, strings.TrimSpace(k), sep); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := SetStringSep(elem, strings.TrimSpace(val), sep); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type: %s", v.Type())
+	}
+
+	return nil
+}
+
+func splitList(s, sep string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, sep)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
@@ -0,0 +1,138 @@
+// Package secrets resolves secret references (file://, ${ENV:NAME} and custom
+// schemes) in config structs. It is shared by the conf and env packages,
+// which re-export its types.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+)
+
+// Resolver resolves secret references found in configuration values.
+type Resolver interface {
+	// Resolve returns the secret referenced by ref.
+	// ok is false when ref is not a reference handled by this resolver,
+	// in which case the value is passed to the next resolver or kept as is.
+	Resolve(ref string) (value string, ok bool, err error)
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface.
+type ResolverFunc func(ref string) (value string, ok bool, err error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (string, bool, error) {
+	return f(ref)
+}
+
+const fileRefPrefix = "file://"
+
+// FileResolver resolves `file:///run/secrets/name` references to the content
+// of the file, without the trailing newline.
+type FileResolver struct{}
+
+func (FileResolver) Resolve(ref string) (string, bool, error) {
+	path, ok := strings.CutPrefix(ref, fileRefPrefix)
+	if !ok {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", true, fmt.Errorf("read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// EnvResolver resolves `${ENV:NAME}` references to the value of the environment variable NAME.
+type EnvResolver struct {
+	// Lookup returns the value of a variable. os.LookupEnv is used if nil.
+	Lookup func(name string) (string, bool)
+}
+
+func (r EnvResolver) Resolve(ref string) (string, bool, error) {
+	if !strings.HasPrefix(ref, "${ENV:") || !strings.HasSuffix(ref, "}") {
+		return "", false, nil
+	}
+	lookup := r.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	name := ref[len("${ENV:") : len(ref)-1]
+	value, ok := lookup(name)
+	if !ok {
+		return "", true, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, true, nil
+}
+
+// DefaultResolvers returns the resolvers used when none are configured:
+// FileResolver and EnvResolver.
+func DefaultResolvers() []Resolver {
+	return []Resolver{FileResolver{}, EnvResolver{}}
+}
+
+// Resolve replaces secret references in the string fields of a struct
+// (including string slices and string map values) with the values they point to.
+// A value is a reference only if it matches a resolver as a whole.
+// Resolvers are tried in order; DefaultResolvers are used if none are given.
+//
+// Errors name the field and never contain the resolved secret.
+func Resolve(cfgPtr interface{}, resolvers ...Resolver) error {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		resolvers = DefaultResolvers()
+	}
+
+	return reflectx.WalkValues(elem, func(f reflectx.Field) error {
+		if err := resolveValue(f.Value, resolvers); err != nil {
+			return fmt.Errorf("error resolving secret for field %s: %w", f.Key(), err)
+		}
+		return nil
+	})
+}
+
+func resolveValue(v reflect.Value, resolvers []Resolver) error {
+	switch {
+	case v.Kind() == reflect.String:
+		resolved, err := resolveString(v.String(), resolvers)
+		if err != nil {
+			return err
+		}
+		v.SetString(resolved)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveValue(v.Index(i), resolvers); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.String:
+		iter := v.MapRange()
+		for iter.Next() {
+			resolved, err := resolveString(iter.Value().String(), resolvers)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+		}
+	}
+	return nil
+}
+
+func resolveString(s string, resolvers []Resolver) (string, error) {
+	for _, r := range resolvers {
+		value, ok, err := r.Resolve(s)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return value, nil
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,173 @@
+// Package validate checks config structs against the rules in their `validate` tags.
+// It is shared by the conf and env packages, which re-export its types.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shanth1/gotools/internal/reflectx"
+	"github.com/shanth1/gotools/ops"
+)
+
+// Validator is implemented by config structs that check their own consistency.
+// Struct calls it after all `validate` tag rules have passed.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a validation rule violated by a single config field.
+type FieldError struct {
+	Field   string // Dotted key of the field (e.g. "email.port")
+	Rule    string // Violated rule (e.g. "required", "max")
+	Param   string // Rule parameter, if any (e.g. "65535")
+	Message string // Human-readable description of the violation
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors holds every violation found in a config struct.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct checks a config struct against the rules in its `validate` tags and,
+// if they all pass, calls its Validate method when it implements Validator.
+// Violations are reported as an *ops.Error of kind ops.KindInvalid for op, wrapping Errors.
+func Struct(op string, cfgPtr interface{}) error {
+	elem, err := reflectx.ExpectStructPtr(cfgPtr)
+	if err != nil {
+		return err
+	}
+
+	var violations Errors
+	err = reflectx.WalkValues(elem, func(f reflectx.Field) error {
+		tag := f.Tag("validate")
+		if tag == "" {
+			return nil
+		}
+		fieldErrs, err := validateField(f.Key(), f.Value, tag)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, fieldErrs...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return ops.Wrap(op, ops.KindInvalid, violations)
+	}
+
+	if v, ok := cfgPtr.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return ops.Wrap(op, ops.KindInvalid, err)
+		}
+	}
+
+	return nil
+}
+
+func validateField(key string, v reflect.Value, tag string) ([]FieldError, error) {
+	var violations []FieldError
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		var msg string
+		switch name {
+		case "":
+			continue
+		case "omitempty":
+			if v.IsZero() {
+				return violations, nil
+			}
+			continue
+		case "required":
+			if v.IsZero() {
+				msg = "is required"
+			}
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter %q for rule %q on field %s", param, name, key)
+			}
+			msg = checkBound(v, name, limit, param)
+		case "oneof":
+			if !isOneOf(v, strings.Fields(param)) {
+				msg = "must be one of [" + strings.Join(strings.Fields(param), ", ") + "]"
+			}
+		default:
+			return nil, fmt.Errorf("unknown validation rule %q on field %s", name, key)
+		}
+
+		if msg != "" {
+			violations = append(violations, FieldError{Field: key, Rule: name, Param: param, Message: msg})
+		}
+	}
+
+	return violations, nil
+}
+
+// checkBound compares numbers by value and strings, slices and maps by length.
+func checkBound(v reflect.Value, rule string, limit float64, param string) string {
+	var n float64
+	subject := ""
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n = float64(v.Len())
+		subject = "length "
+	default:
+		return ""
+	}
+
+	switch {
+	case rule == "min" && n < limit:
+		return "must be at least " + subject + param
+	case rule == "max" && n > limit:
+		return "must be at most " + subject + param
+	case rule == "len" && n != limit:
+		return "must have length " + param
+	}
+	return ""
+}
+
+func isOneOf(v reflect.Value, allowed []string) bool {
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(v.Uint(), 10)
+	default:
+		s = fmt.Sprint(v.Interface())
+	}
+
+	for _, a := range allowed {
+		if s == a {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/rs/zerolog"
+	"github.com/shanth1/gotools/logkeys"
 )
 
 // ToSlog converts the current Logger into a standard library *slog.Logger.
@@ -12,8 +13,13 @@ import (
 func ToSlog(l Logger) *slog.Logger {
 	// Try to get the underlying zerolog logger for better performance
 	if adapter, ok := l.(*zerologAdapter); ok {
+		logger := adapter.logger
+		if adapter.component != "" {
+			logger = logger.With().Str(logkeys.Component, adapter.component).Logger()
+		}
 		return slog.New(&zeroSlogHandler{
-			logger: adapter.logger,
+			logger: logger,
+			level:  adapter.cfg.levelVar,
 		})
 	}
 
@@ -26,16 +32,20 @@ func ToSlog(l Logger) *slog.Logger {
 // zeroSlogHandler implements slog.Handler
 type zeroSlogHandler struct {
 	logger zerolog.Logger
+	level  *LevelVar
 	group  string
 }
 
 func (h *zeroSlogHandler) Enabled(_ context.Context, level slog.Level) bool {
 	zLevel := slogLevelToZerolog(level)
-	return h.logger.GetLevel() <= zLevel
+	return h.level.Enabled(zerologToLevel(zLevel)) && h.logger.GetLevel() <= zLevel
 }
 
 func (h *zeroSlogHandler) Handle(ctx context.Context, record slog.Record) error {
 	zLevel := slogLevelToZerolog(record.Level)
+	if !h.level.Enabled(zerologToLevel(zLevel)) {
+		return nil
+	}
 
 	// Create a zerolog event
 	event := h.logger.WithLevel(zLevel)
@@ -82,6 +92,7 @@ func (h *zeroSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 
 	return &zeroSlogHandler{
 		logger: zCtx.Logger(),
+		level:  h.level,
 		group:  h.group,
 	}
 }
@@ -96,6 +107,7 @@ func (h *zeroSlogHandler) WithGroup(name string) slog.Handler {
 	// Simplified implementation:
 	return &zeroSlogHandler{
 		logger: h.logger,
+		level:  h.level,
 		group:  name, // Limitation: only supports one level of grouping in this simple adapter
 	}
 }
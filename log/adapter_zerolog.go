@@ -10,13 +10,17 @@ import (
 
 // zerologAdapter implements the Logger interface
 type zerologAdapter struct {
-	logger zerolog.Logger
-	cfg    *config
+	logger    zerolog.Logger
+	cfg       *config
+	dedup     map[Level]*deduper // Shared by the loggers derived with With and Component
+	component string             // Added to every event, replaced by Component
 }
 
 // zerologEvent implements the Event interface
 type zerologEvent struct {
-	event *zerolog.Event
+	event  *zerolog.Event
+	level  Level
+	logger *zerologAdapter // Set only if the level deduplicates messages
 }
 
 func newZerologLogger(opts ...option) Logger {
@@ -42,16 +46,19 @@ func (l *zerologAdapter) With(fields ...Field) Logger {
 		context = context.Interface(f.Key, f.Value)
 	}
 	return &zerologAdapter{
-		logger: context.Logger(),
-		cfg:    l.cfg,
+		logger:    context.Logger(),
+		cfg:       l.cfg,
+		dedup:     l.dedup,
+		component: l.component,
 	}
 }
 
 func (l *zerologAdapter) WithLevel(level Level) Event {
-	zLevel := mapToZerologLevel(level)
-	return &zerologEvent{
-		event: l.logger.WithLevel(zLevel),
+	if !l.cfg.levelVar.Enabled(level) {
+		return &zerologEvent{}
 	}
+	zLevel := mapToZerologLevel(level)
+	return l.event(level, l.logger.WithLevel(zLevel))
 }
 
 func (l *zerologAdapter) WithOptions(opts ...option) Logger {
@@ -66,13 +73,33 @@ func (l *zerologAdapter) WithOptions(opts ...option) Logger {
 
 // --- Logger ---
 
-func (l *zerologAdapter) Trace() Event { return &zerologEvent{l.logger.Trace()} }
-func (l *zerologAdapter) Debug() Event { return &zerologEvent{l.logger.Debug()} }
-func (l *zerologAdapter) Info() Event  { return &zerologEvent{l.logger.Info()} }
-func (l *zerologAdapter) Warn() Event  { return &zerologEvent{l.logger.Warn()} }
-func (l *zerologAdapter) Error() Event { return &zerologEvent{l.logger.Error()} }
-func (l *zerologAdapter) Fatal() Event { return &zerologEvent{l.logger.Fatal()} }
-func (l *zerologAdapter) Panic() Event { return &zerologEvent{l.logger.Panic()} }
+func (l *zerologAdapter) Trace() Event { return l.newEvent(LevelTrace, l.logger.Trace) }
+func (l *zerologAdapter) Debug() Event { return l.newEvent(LevelDebug, l.logger.Debug) }
+func (l *zerologAdapter) Info() Event  { return l.newEvent(LevelInfo, l.logger.Info) }
+func (l *zerologAdapter) Warn() Event  { return l.newEvent(LevelWarn, l.logger.Warn) }
+func (l *zerologAdapter) Error() Event { return l.newEvent(LevelError, l.logger.Error) }
+func (l *zerologAdapter) Fatal() Event { return l.newEvent(LevelFatal, l.logger.Fatal) }
+func (l *zerologAdapter) Panic() Event { return l.newEvent(LevelPanic, l.logger.Panic) }
+
+// newEvent checks the level before creating the zerolog event, so that changes
+// to the LevelVar apply immediately. A nil zerolog event discards all calls.
+func (l *zerologAdapter) newEvent(level Level, create func() *zerolog.Event) Event {
+	if !l.cfg.levelVar.Enabled(level) {
+		return &zerologEvent{}
+	}
+	return l.event(level, create())
+}
+
+func (l *zerologAdapter) event(level Level, event *zerolog.Event) *zerologEvent {
+	if l.component != "" {
+		event.Str(logkeys.Component, l.component)
+	}
+	e := &zerologEvent{event: event, level: level}
+	if l.dedup[level] != nil {
+		e.logger = l
+	}
+	return e
+}
 
 // --- Event Implementation ---
 
@@ -132,27 +159,40 @@ func (e *zerologEvent) Fields(fields ...Field) Event {
 }
 
 func (e *zerologEvent) Msg(msg string) {
+	if !e.allow(msg) {
+		return
+	}
 	e.event.Msg(msg)
 }
 
 func (e *zerologEvent) Msgf(format string, v ...interface{}) {
+	if !e.allow(format) {
+		return
+	}
 	e.event.Msgf(format, v...)
 }
 
+// allow applies deduplication by message key, discarding the event if it is a repetition.
+// Events already dropped by the level or the sampler are not counted.
+func (e *zerologEvent) allow(key string) bool {
+	if e.logger == nil || e.event == nil {
+		return true
+	}
+	if e.logger.dedup[e.level].allow(key, dedupSummary(e.logger, e.level, key)) {
+		return true
+	}
+	e.event.Discard()
+	return false
+}
+
 // --- Constructor ---
 func newLoggerWithConfig(cfg *config) Logger {
-	strLevel := levelToString(cfg.level)
-
-	var zlevel zerolog.Level
-	if cfg.level == LevelDisabled {
-		zlevel = zerolog.Disabled
-	} else {
-		var err error
-		zlevel, err = zerolog.ParseLevel(strLevel)
-		if err != nil {
-			zlevel = zerolog.InfoLevel
-		}
+	// The level is checked against the LevelVar when events are created,
+	// so the zerolog logger itself lets every level through.
+	if cfg.levelVar == nil {
+		cfg.levelVar = NewLevelVar(cfg.level)
 	}
+	zlevel := zerolog.TraceLevel
 
 	var finalWriter io.Writer
 	if len(cfg.writers) == 1 {
@@ -178,10 +218,14 @@ func newLoggerWithConfig(cfg *config) Logger {
 		e.Str(zerolog.TimestampFieldName, time.Now().Format(time.RFC3339Nano))
 	})
 	finalLogger := loggerWithContext.Hook(nanoSecondHook).Level(zlevel)
+	if sampler, ok := newSampler(cfg.sampling); ok {
+		finalLogger = finalLogger.Sample(sampler)
+	}
 
 	return &zerologAdapter{
 		logger: finalLogger,
 		cfg:    cfg,
+		dedup:  newDedupers(cfg.sampling),
 	}
 }
 
@@ -208,3 +252,27 @@ func mapToZerologLevel(l Level) zerolog.Level {
 		return zerolog.InfoLevel
 	}
 }
+
+// Helper to convert zerolog.Level to internal Level
+func zerologToLevel(l zerolog.Level) Level {
+	switch l {
+	case zerolog.TraceLevel:
+		return LevelTrace
+	case zerolog.DebugLevel:
+		return LevelDebug
+	case zerolog.InfoLevel:
+		return LevelInfo
+	case zerolog.WarnLevel:
+		return LevelWarn
+	case zerolog.ErrorLevel:
+		return LevelError
+	case zerolog.FatalLevel:
+		return LevelFatal
+	case zerolog.PanicLevel:
+		return LevelPanic
+	case zerolog.Disabled:
+		return LevelDisabled
+	default:
+		return LevelInfo
+	}
+}
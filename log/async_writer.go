@@ -0,0 +1,372 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultQueueSize is the queue size of an AsyncWriter when AsyncConfig.QueueSize is not set.
+const defaultQueueSize = 1024
+
+// OverflowPolicy defines what an AsyncWriter does with a record when its queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest drops the record being written. This is the default.
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest queued record to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock waits until the queue has room.
+	// CAUTION: A slow writer then slows down the callers again.
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// AsyncConfig configures an AsyncWriter.
+type AsyncConfig struct {
+	// Enabled makes WithConfig write logs asynchronously.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled" toml:"enabled"`
+
+	// QueueSize is the maximum number of queued records. Defaults to 1024.
+	QueueSize int `mapstructure:"queue_size" yaml:"queue_size" json:"queue_size" toml:"queue_size"`
+
+	// Policy is applied when the queue is full: drop_newest (default), drop_oldest or block.
+	Policy OverflowPolicy `mapstructure:"policy" yaml:"policy" json:"policy" toml:"policy"`
+}
+
+// AsyncWriter is an io.Writer that queues records and writes them to another
+// writer in a background goroutine, so that slow writers (e.g. network ones)
+// do not block the callers. It is safe for concurrent use.
+//
+// Records that do not fit in the queue are handled according to the OverflowPolicy
+// and counted by Dropped. Call Close before exiting to write the queued records.
+type AsyncWriter struct {
+	out    io.Writer
+	policy OverflowPolicy
+	queue  chan asyncRecord
+
+	// closeMu guards the queue against sends after Close.
+	closeMu sync.RWMutex
+	closed  bool
+	stop    chan struct{} // Closed by Close to release blocked writers
+	stopped sync.Once
+	done    chan struct{} // Closed when the queue is drained
+	abort   atomic.Bool   // Set when Close gives up; the rest of the queue is dropped
+
+	// sendMu keeps the queue in sequence order: records are numbered and sent
+	// under it, and lastSeq is the number of the last record queued.
+	sendMu  sync.Mutex
+	lastSeq atomic.Uint64
+	dropped atomic.Uint64
+
+	// writeMu serializes the writes of the worker and of WriteLevel to out.
+	writeMu sync.Mutex
+
+	progressMu sync.Mutex
+	handled    uint64 // Sequence number of the last record handled by the worker
+	waiters    []*flushWaiter
+}
+
+type asyncRecord struct {
+	seq   uint64
+	level zerolog.Level
+	p     []byte
+}
+
+type flushWaiter struct {
+	target uint64
+	done   chan struct{}
+}
+
+// syncFlushTimeout bounds the wait for the queued records before a fatal or
+// panic record is written.
+const syncFlushTimeout = 5 * time.Second
+
+// NewAsyncWriter starts writing the records queued on the returned writer to out.
+// It returns an error if the policy is unknown.
+func NewAsyncWriter(out io.Writer, cfg AsyncConfig) (*AsyncWriter, error) {
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = OverflowDropNewest
+	case OverflowDropNewest, OverflowDropOldest, OverflowBlock:
+	default:
+		return nil, fmt.Errorf("unknown overflow policy: %q. Valid policies: %s, %s, %s",
+			cfg.Policy, OverflowBlock, OverflowDropNewest, OverflowDropOldest)
+	}
+
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+
+	w := &AsyncWriter{
+		out:    out,
+		policy: cfg.Policy,
+		queue:  make(chan asyncRecord, size),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write queues a copy of p. It never reports dropped records as errors;
+// see Dropped. It returns os.ErrClosed after Close.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	return w.enqueue(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. Fatal and panic records bypass
+// the queue: the queued records are flushed first (for up to 5 seconds), then
+// the record is written synchronously, even after Close, because the program
+// exits or panics right after it. Other records are queued like with Write.
+func (w *AsyncWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level != zerolog.FatalLevel && level != zerolog.PanicLevel {
+		return w.enqueue(level, p)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncFlushTimeout)
+	defer cancel()
+	_ = w.Flush(ctx)
+	return w.write(level, p)
+}
+
+func (w *AsyncWriter) enqueue(level zerolog.Level, p []byte) (int, error) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	// The caller may reuse p (zerolog does), so the queue keeps a copy.
+	record := asyncRecord{level: level, p: make([]byte, len(p))}
+	copy(record.p, p)
+
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	record.seq = w.lastSeq.Load() + 1
+	switch w.policy {
+	case OverflowBlock:
+		select {
+		case w.queue <- record:
+		case <-w.stop:
+			return 0, os.ErrClosed
+		}
+	case OverflowDropOldest:
+		for !w.trySend(record) {
+			select {
+			case <-w.queue:
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		if !w.trySend(record) {
+			w.dropped.Add(1)
+			return len(p), nil
+		}
+	}
+	w.lastSeq.Store(record.seq)
+	return len(p), nil
+}
+
+func (w *AsyncWriter) trySend(record asyncRecord) bool {
+	select {
+	case w.queue <- record:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full
+// or because Close gave up before they were written.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush waits until the records queued before the call are written,
+// or until ctx is done, in which case it returns ctx.Err().
+// Records dropped from the queue in the meantime are not waited for.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	w.progressMu.Lock()
+	target := w.lastSeq.Load()
+	if w.handled >= target {
+		w.progressMu.Unlock()
+		return nil
+	}
+	waiter := &flushWaiter{target: target, done: make(chan struct{})}
+	w.waiters = append(w.waiters, waiter)
+	w.progressMu.Unlock()
+
+	select {
+	case <-waiter.done:
+		return nil
+	case <-ctx.Done():
+		w.progressMu.Lock()
+		for i, other := range w.waiters {
+			if other == waiter {
+				w.waiters = append(w.waiters[:i], w.waiters[i+1:]...)
+				break
+			}
+		}
+		w.progressMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting records and writes the queued ones until ctx is done.
+// Pass the shutdown context of ctx.WithGracefulShutdown to bound the time spent.
+// Records still queued when ctx is done are dropped and ctx.Err() is returned.
+// The underlying writer is not closed, as it may be shared (e.g. os.Stdout).
+func (w *AsyncWriter) Close(ctx context.Context) error {
+	// Writers blocked on a full queue hold closeMu, so they are released first.
+	w.stopped.Do(func() { close(w.stop) })
+
+	w.closeMu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.closeMu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.abort.Store(true)
+		return ctx.Err()
+	}
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for record := range w.queue {
+		if w.abort.Load() {
+			w.dropped.Add(1)
+		} else if _, err := w.write(record.level, record.p); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to write log record: %v\n", err)
+		}
+		w.markHandled(record.seq)
+	}
+}
+
+// write writes a record to out, keeping its level for zerolog.LevelWriter outputs.
+func (w *AsyncWriter) write(level zerolog.Level, p []byte) (int, error) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	if lw, ok := w.out.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.out.Write(p)
+}
+
+// markHandled records that the worker is done with the record seq, and with
+// every record before it, and releases the Flush calls waiting for them.
+func (w *AsyncWriter) markHandled(seq uint64) {
+	w.progressMu.Lock()
+	defer w.progressMu.Unlock()
+
+	w.handled = seq
+	waiters := w.waiters[:0]
+	for _, waiter := range w.waiters {
+		if waiter.target <= seq {
+			close(waiter.done)
+			continue
+		}
+		waiters = append(waiters, waiter)
+	}
+	w.waiters = waiters
+}
+
+// WithAsync makes the writers added before it asynchronous: they are combined
+// behind a single AsyncWriter. Writers added after it stay synchronous.
+// Use Flush and Close to write the queued records, e.g. on shutdown.
+func WithAsync(cfg AsyncConfig) option {
+	return func(c *config) {
+		var out io.Writer
+		switch len(c.writers) {
+		case 0:
+			return
+		case 1:
+			out = c.writers[0]
+		default:
+			out = zerolog.MultiLevelWriter(c.writers...)
+		}
+		w, err := NewAsyncWriter(out, cfg)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to create async log writer: %v\n", err)
+			return
+		}
+		c.writers = []io.Writer{w}
+	}
+}
+
+// Flush logs the summaries of the messages suppressed so far by deduplication
+// (see SamplingConfig.Dedup), then waits until the records queued by the
+// AsyncWriters of the logger are written, or until ctx is done.
+func Flush(ctx context.Context, logger Logger) error {
+	flushDedupers(logger, false)
+
+	var errs []error
+	for _, w := range asyncWriters(logger) {
+		if err := w.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close logs the pending deduplication summaries, closes the AsyncWriters of
+// the logger (see AsyncWriter.Close), then the files opened by WithFileWriter
+// or the File section of WithConfig, typically with the shutdown context of
+// ctx.WithGracefulShutdown:
+//
+//	appCtx, shutdownCtx, cancel, shutdownCancel := ctx.WithGracefulShutdown(5 * time.Second)
+//	...
+//	<-appCtx.Done()
+//	_ = log.Close(shutdownCtx, logger)
+//
+// The logger, and every logger derived from it, must not be used afterwards.
+func Close(ctx context.Context, logger Logger) error {
+	flushDedupers(logger, true)
+
+	var errs []error
+	for _, w := range asyncWriters(logger) {
+		if err := w.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if adapter, ok := logger.(*zerologAdapter); ok {
+		for _, w := range adapter.cfg.files {
+			if err := w.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func asyncWriters(logger Logger) []*AsyncWriter {
+	adapter, ok := logger.(*zerologAdapter)
+	if !ok {
+		return nil
+	}
+	var writers []*AsyncWriter
+	for _, w := range adapter.cfg.writers {
+		if aw, ok := w.(*AsyncWriter); ok {
+			writers = append(writers, aw)
+		}
+	}
+	return writers
+}
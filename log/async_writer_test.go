@@ -0,0 +1,286 @@
+package log
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks every write until the gate is opened.
+type gatedWriter struct {
+	lockedBuffer
+	gate chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.lockedBuffer.Write(p)
+}
+
+func (w *gatedWriter) lines() []string {
+	return strings.Fields(w.String())
+}
+
+// fill writes the first record, waits for the worker to take it, then writes the others.
+func fill(t *testing.T, w *AsyncWriter, records ...string) {
+	t.Helper()
+	_, err := w.Write([]byte(records[0] + "\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(w.queue) == 0 }, time.Second, time.Millisecond)
+	for _, r := range records[1:] {
+		_, err := w.Write([]byte(r + "\n"))
+		require.NoError(t, err)
+	}
+}
+
+func TestAsyncWriter_Policies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy  OverflowPolicy
+		want    []string
+		dropped uint64
+	}{
+		{policy: "", want: []string{"1", "2", "3"}, dropped: 2},
+		{policy: OverflowDropNewest, want: []string{"1", "2", "3"}, dropped: 2},
+		{policy: OverflowDropOldest, want: []string{"1", "4", "5"}, dropped: 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			t.Parallel()
+			out := newGatedWriter()
+			w, err := NewAsyncWriter(out, AsyncConfig{QueueSize: 2, Policy: tt.policy})
+			require.NoError(t, err)
+
+			// "1" is taken by the worker and blocked in out, "2" and "3" fill the queue.
+			fill(t, w, "1", "2", "3", "4", "5")
+			assert.Equal(t, tt.dropped, w.Dropped())
+
+			close(out.gate)
+			require.NoError(t, w.Flush(context.Background()))
+			assert.Equal(t, tt.want, out.lines())
+		})
+	}
+}
+
+func TestAsyncWriter_Block(t *testing.T) {
+	t.Parallel()
+	out := newGatedWriter()
+	w, err := NewAsyncWriter(out, AsyncConfig{QueueSize: 1, Policy: OverflowBlock})
+	require.NoError(t, err)
+
+	fill(t, w, "1", "2")
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("3\n"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write did not block on a full queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(out.gate)
+	<-written
+	require.NoError(t, w.Close(context.Background()))
+	assert.Equal(t, []string{"1", "2", "3"}, out.lines())
+	assert.Zero(t, w.Dropped())
+}
+
+func TestAsyncWriter_InvalidPolicy(t *testing.T) {
+	t.Parallel()
+	_, err := NewAsyncWriter(newGatedWriter(), AsyncConfig{Policy: "lossy"})
+	assert.ErrorContains(t, err, `unknown overflow policy: "lossy"`)
+}
+
+func TestAsyncWriter_FlushAndClose(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flush stops at context deadline", func(t *testing.T) {
+		t.Parallel()
+		out := newGatedWriter()
+		w, err := NewAsyncWriter(out, AsyncConfig{})
+		require.NoError(t, err)
+		fill(t, w, "1", "2")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, w.Flush(ctx), context.DeadlineExceeded)
+
+		close(out.gate)
+		require.NoError(t, w.Flush(context.Background()))
+		assert.Equal(t, []string{"1", "2"}, out.lines())
+	})
+
+	t.Run("flush waits for queued records while the queue overflows", func(t *testing.T) {
+		t.Parallel()
+		for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest} {
+			out := newGatedWriter()
+			w, err := NewAsyncWriter(out, AsyncConfig{QueueSize: 2, Policy: policy})
+			require.NoError(t, err)
+			fill(t, w, "1", "2", "3")
+
+			flushed := make(chan error, 1)
+			go func() { flushed <- w.Flush(context.Background()) }()
+			time.Sleep(10 * time.Millisecond)
+
+			// Dropped records must not count as progress for the pending Flush.
+			for i := 4; i < 20; i++ {
+				_, err := w.Write([]byte(strconv.Itoa(i) + "\n"))
+				require.NoError(t, err)
+			}
+			select {
+			case <-flushed:
+				t.Fatalf("%s: flush returned before the queued records were written", policy)
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			close(out.gate)
+			require.NoError(t, <-flushed)
+			assert.Contains(t, out.lines(), "1", policy)
+			if policy == OverflowDropNewest {
+				assert.Equal(t, []string{"1", "2", "3"}, out.lines()[:3])
+			}
+			require.NoError(t, w.Close(context.Background()))
+		}
+	})
+
+	t.Run("close drops what is left at context deadline", func(t *testing.T) {
+		t.Parallel()
+		out := newGatedWriter()
+		w, err := NewAsyncWriter(out, AsyncConfig{})
+		require.NoError(t, err)
+		fill(t, w, "1", "2", "3")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+
+		_, err = w.Write([]byte("4\n"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+
+		close(out.gate)
+		assert.Eventually(t, func() bool { return w.Dropped() == 2 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"1"}, out.lines())
+	})
+
+	t.Run("close releases blocked writers", func(t *testing.T) {
+		t.Parallel()
+		out := newGatedWriter()
+		w, err := NewAsyncWriter(out, AsyncConfig{QueueSize: 1, Policy: OverflowBlock})
+		require.NoError(t, err)
+		fill(t, w, "1", "2")
+
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := w.Write([]byte("3\n"))
+			errCh <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+		assert.ErrorIs(t, <-errCh, os.ErrClosed)
+		close(out.gate)
+	})
+}
+
+func TestAsyncWriter_WriteLevel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fatal and panic records are written synchronously after the queue", func(t *testing.T) {
+		t.Parallel()
+		out := newGatedWriter()
+		w, err := NewAsyncWriter(out, AsyncConfig{})
+		require.NoError(t, err)
+		fill(t, w, "1", "2")
+
+		time.AfterFunc(10*time.Millisecond, func() { close(out.gate) })
+		_, err = w.WriteLevel(zerolog.PanicLevel, []byte("panic\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "panic"}, out.lines())
+
+		require.NoError(t, w.Close(context.Background()))
+		_, err = w.WriteLevel(zerolog.FatalLevel, []byte("fatal\n"))
+		require.NoError(t, err, "fatal records are written even after Close")
+		assert.Equal(t, []string{"1", "2", "panic", "fatal"}, out.lines())
+	})
+
+	t.Run("other levels are queued", func(t *testing.T) {
+		t.Parallel()
+		out := newGatedWriter()
+		w, err := NewAsyncWriter(out, AsyncConfig{})
+		require.NoError(t, err)
+
+		_, err = w.WriteLevel(zerolog.ErrorLevel, []byte("error\n"))
+		require.NoError(t, err)
+		assert.Empty(t, out.lines())
+
+		close(out.gate)
+		require.NoError(t, w.Close(context.Background()))
+		assert.Equal(t, []string{"error"}, out.lines())
+	})
+
+	t.Run("logger panic", func(t *testing.T) {
+		t.Parallel()
+		var buf lockedBuffer
+		logger := New(WithWriter(&buf), WithAsync(AsyncConfig{}))
+
+		logger.Info().Msg("before")
+		assert.Panics(t, func() { logger.Panic().Msg("boom") })
+		assert.Contains(t, buf.String(), "before")
+		assert.Contains(t, buf.String(), "boom", "written before the panic, without Flush")
+		require.NoError(t, Close(context.Background(), logger))
+	})
+}
+
+func TestLogger_Async(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with async", func(t *testing.T) {
+		t.Parallel()
+		var buf lockedBuffer
+		logger := New(WithWriter(&buf), WithAsync(AsyncConfig{}))
+
+		logger.Info().Msg("first")
+		logger.Component("db").Info().Msg("second")
+		require.NoError(t, Flush(context.Background(), logger))
+		assert.Contains(t, buf.String(), "first")
+		assert.Contains(t, buf.String(), "second")
+
+		require.NoError(t, Close(context.Background(), logger))
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		t.Parallel()
+		logger := NewFromConfig(Config{
+			Level:      "info",
+			JSONOutput: true,
+			Async:      AsyncConfig{Enabled: true, QueueSize: 16, Policy: OverflowDropOldest},
+		})
+
+		assert.Len(t, asyncWriters(logger), 1)
+		assert.NoError(t, Close(context.Background(), logger))
+	})
+
+	t.Run("synchronous logger", func(t *testing.T) {
+		t.Parallel()
+		logger := New(WithWriter(&lockedBuffer{}))
+		assert.NoError(t, Flush(context.Background(), logger))
+		assert.NoError(t, Close(context.Background(), logger))
+	})
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"strings"
+)
+
+// ComponentLevel is a level override in Config.Components.
+// Overrides are a list rather than a map keyed by name, because config loaders
+// such as viper split keys on "." and lower-case them.
+type ComponentLevel struct {
+	// Name is a component name, or a pattern ending with "*" (e.g. "db.*").
+	Name string `mapstructure:"name" yaml:"name" json:"name" toml:"name"`
+
+	// Level is the level of the matching components (see Config.Level).
+	Level string `mapstructure:"level" yaml:"level" json:"level" toml:"level"`
+}
+
+// WithComponentLevels sets level overrides for loggers created with Logger.Component.
+// Keys are component names, or patterns ending with "*" that match every name
+// with the given prefix (e.g. "db.*" matches "db.pool" and "db.tx").
+// An exact name wins over patterns, and longer patterns win over shorter ones.
+func WithComponentLevels(levels map[string]Level) option {
+	return func(c *config) {
+		c.components = make(map[string]Level, len(levels))
+		for name, level := range levels {
+			c.components[name] = level
+		}
+	}
+}
+
+// Component keeps the component name out of the zerolog context, so that
+// a nested call replaces the field instead of adding a second one.
+func (l *zerologAdapter) Component(name string) Logger {
+	cfg := l.cfg.clone()
+	if level, ok := componentLevel(cfg.components, name); ok {
+		cfg.levelVar = NewLevelVar(level)
+	}
+	return &zerologAdapter{
+		logger:    l.logger,
+		cfg:       cfg,
+		dedup:     l.dedup,
+		component: name,
+	}
+}
+
+// componentLevel returns the override matching the component name.
+func componentLevel(levels map[string]Level, name string) (Level, bool) {
+	if level, ok := levels[name]; ok {
+		return level, true
+	}
+
+	var (
+		best  Level
+		found bool
+		size  = -1
+	)
+	for pattern, level := range levels {
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if !ok || !strings.HasPrefix(name, prefix) || len(prefix) <= size {
+			continue
+		}
+		best, found, size = level, true, len(prefix)
+	}
+	return best, found
+}
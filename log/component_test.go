@@ -0,0 +1,135 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Component(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applies matching overrides", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithLevel(LevelInfo), WithComponentLevels(map[string]Level{
+			"db.*":    LevelDebug,
+			"db.tx.*": LevelError,
+			"http":    LevelWarn,
+		}))
+
+		logger.Component("db.pool").Debug().Msg("pool debug")
+		logger.Component("db.tx.commit").Warn().Msg("tx warn")
+		logger.Component("http").Info().Msg("http info")
+		logger.Component("cache").Info().Msg("cache info")
+		logger.Component("cache").Debug().Msg("cache debug")
+
+		output := buf.String()
+		assert.Contains(t, output, `"component":"db.pool"`)
+		assert.Contains(t, output, "pool debug")
+		assert.NotContains(t, output, "tx warn", "the longest pattern wins")
+		assert.NotContains(t, output, "http info")
+		assert.Contains(t, output, "cache info")
+		assert.NotContains(t, output, "cache debug")
+	})
+
+	t.Run("components without override follow the level var", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		lv := NewLevelVar(LevelInfo)
+		logger := New(WithWriter(&buf), WithLevelVar(lv), WithComponentLevels(map[string]Level{"db.*": LevelError}))
+		cache := logger.Component("cache")
+		db := logger.Component("db.pool")
+
+		lv.Set(LevelDebug)
+		cache.Debug().Msg("cache debug")
+		db.Warn().Msg("db warn")
+
+		assert.Contains(t, buf.String(), "cache debug")
+		assert.NotContains(t, buf.String(), "db warn")
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := NewFromConfig(Config{
+			Level:      "warn",
+			Components: []ComponentLevel{{Name: "worker*", Level: "debug"}},
+		}).WithOptions(WithWriter(&buf))
+
+		logger.Component("worker.email").Debug().Msg("worker debug")
+		logger.Info().Msg("root info")
+
+		assert.Contains(t, buf.String(), "worker debug")
+		assert.NotContains(t, buf.String(), "root info")
+	})
+
+	t.Run("config names keep their dots and case", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := NewFromConfig(Config{
+			Level: "warn",
+			Components: []ComponentLevel{
+				{Name: "db.*", Level: "debug"},
+				{Name: "HTTP.Server", Level: "info"},
+			},
+		}).WithOptions(WithWriter(&buf))
+
+		logger.Component("db.pool").Debug().Msg("pool debug")
+		logger.Component("HTTP.Server").Info().Msg("server info")
+		logger.Component("cache").Info().Msg("cache info")
+
+		assert.Contains(t, buf.String(), "pool debug")
+		assert.Contains(t, buf.String(), "server info")
+		assert.NotContains(t, buf.String(), "cache info")
+	})
+
+	t.Run("nested components replace the field", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithComponentLevels(map[string]Level{"db.pool": LevelDebug}))
+
+		logger.Component("db").With(Str("request_id", "42")).Component("db.pool").Debug().Msg("nested")
+
+		output := buf.String()
+		assert.Equal(t, 1, strings.Count(output, `"component"`))
+		assert.Contains(t, output, `"component":"db.pool"`)
+		assert.Contains(t, output, `"request_id":"42"`)
+		assert.Contains(t, output, "nested")
+	})
+
+	t.Run("invalid config level panics", func(t *testing.T) {
+		t.Parallel()
+		assert.Panics(t, func() {
+			NewFromConfig(Config{Components: []ComponentLevel{{Name: "db", Level: "loud"}}})
+		})
+	})
+}
+
+func TestComponentLevel(t *testing.T) {
+	t.Parallel()
+	levels := map[string]Level{
+		"*":      LevelError,
+		"db":     LevelWarn,
+		"db.*":   LevelDebug,
+		"db.tx*": LevelTrace,
+	}
+
+	tests := map[string]Level{
+		"db":       LevelWarn,
+		"db.pool":  LevelDebug,
+		"db.tx":    LevelTrace,
+		"db.txlog": LevelTrace,
+		"http":     LevelError,
+	}
+	for name, want := range tests {
+		got, ok := componentLevel(levels, name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, ok := componentLevel(map[string]Level{"db.*": LevelDebug}, "http")
+	assert.False(t, ok)
+}
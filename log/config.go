@@ -5,6 +5,14 @@ type Config struct {
 	// Valid values: trace, debug, info, warn, error, fatal, panic, disabled, off, none.
 	Level string `mapstructure:"level" yaml:"level" json:"level" toml:"level"`
 
+	// Components overrides the level of loggers created with Logger.Component,
+	// by component name or prefix pattern (see ComponentLevel).
+	Components []ComponentLevel `mapstructure:"components" yaml:"components" json:"components" toml:"components"`
+
+	// Sampling limits the events logged per level, keyed by level name,
+	// or "*" for every level without its own entry (see SamplingConfig).
+	Sampling map[string]SamplingConfig `mapstructure:"sampling" yaml:"sampling" json:"sampling" toml:"sampling"`
+
 	// App name to be included in all logs.
 	App string `mapstructure:"app" yaml:"app" json:"app" toml:"app"`
 
@@ -22,4 +30,11 @@ type Config struct {
 
 	// JSONOutput enforces JSON output even if a terminal is detected.
 	JSONOutput bool `mapstructure:"json_output" yaml:"json_output" json:"json_output" toml:"json_output"`
+
+	// File writes JSON logs to a rotating file if File.Path is set.
+	File FileConfig `mapstructure:"file" yaml:"file" json:"file" toml:"file"`
+
+	// Async queues the logs of all the writers above and writes them in the background
+	// if Async.Enabled is set. Use log.Close on shutdown to write the queued logs.
+	Async AsyncConfig `mapstructure:"async" yaml:"async" json:"async" toml:"async"`
 }
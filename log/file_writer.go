@@ -0,0 +1,308 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the timestamp inserted in the names of rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// FileConfig configures a rotating log file.
+type FileConfig struct {
+	// Path of the log file. Rotated files are kept next to it,
+	// named with a timestamp (e.g. "app-2024-05-01T10-00-00.000000000.log").
+	Path string `mapstructure:"path" yaml:"path" json:"path" toml:"path"`
+
+	// MaxSize is the size in megabytes after which the file is rotated. 0 disables size rotation.
+	MaxSize int `mapstructure:"max_size" yaml:"max_size" json:"max_size" toml:"max_size"`
+
+	// MaxAge is the time after which the file is rotated, counted from when it was opened.
+	// 0 disables age rotation.
+	MaxAge time.Duration `mapstructure:"max_age" yaml:"max_age" json:"max_age" toml:"max_age"`
+
+	// MaxBackups is the number of rotated files to keep. 0 keeps all of them.
+	MaxBackups int `mapstructure:"max_backups" yaml:"max_backups" json:"max_backups" toml:"max_backups"`
+
+	// Compress gzips rotated files.
+	Compress bool `mapstructure:"compress" yaml:"compress" json:"compress" toml:"compress"`
+}
+
+// FileWriter is an io.Writer that writes to a file, rotates it by size and age,
+// and reopens it on SIGHUP (e.g. after an external logrotate).
+// It is safe for concurrent use.
+type FileWriter struct {
+	cfg      FileConfig
+	maxBytes int64
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+
+	signals chan os.Signal
+	done    chan struct{}
+	closed  bool
+}
+
+// NewFileWriter opens (or creates) the log file described by cfg,
+// creating its directory if needed.
+func NewFileWriter(cfg FileConfig) (*FileWriter, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is empty")
+	}
+
+	w := &FileWriter{
+		cfg:      cfg,
+		maxBytes: int64(cfg.MaxSize) * 1024 * 1024,
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	signal.Notify(w.signals, syscall.SIGHUP)
+	go w.watchSignals()
+
+	return w, nil
+}
+
+// Write writes p to the file, rotating it first if p would exceed MaxSize
+// or the file is older than MaxAge.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it with a timestamp and opens a new one.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes and reopens the file at the configured path without renaming it.
+// It is called on SIGHUP.
+func (w *FileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	return w.open()
+}
+
+// Close stops listening for SIGHUP, waits for pending compression and
+// removal of old backups, and closes the file.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	signal.Stop(w.signals)
+	close(w.done)
+	err := w.file.Close()
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+func (w *FileWriter) watchSignals() {
+	for {
+		select {
+		case <-w.signals:
+			if err := w.Reopen(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Failed to reopen log file %s: %v\n", w.cfg.Path, err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *FileWriter) shouldRotate(n int64) bool {
+	if w.maxBytes > 0 && w.size > 0 && w.size+n > w.maxBytes {
+		return true
+	}
+	return w.cfg.MaxAge > 0 && time.Since(w.openedAt) >= w.cfg.MaxAge
+}
+
+func (w *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	f, err := os.OpenFile(w.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	w.file = f
+	w.size = info.Size()
+	w.openedAt = time.Now()
+	return nil
+}
+
+func (w *FileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	backup := w.backupName(time.Now())
+	if err := os.Rename(w.cfg.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rename log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.mill(backup)
+	}()
+	return nil
+}
+
+// mill compresses a new backup and removes the backups beyond MaxBackups.
+// Runs are serialized so that concurrent rotations do not race on the directory.
+func (w *FileWriter) mill(backup string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.cfg.Compress {
+		if err := compressFile(backup); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to compress log file %s: %v\n", backup, err)
+		}
+	}
+
+	if w.cfg.MaxBackups <= 0 {
+		return
+	}
+	backups, err := w.backups()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to list log backups: %v\n", err)
+		return
+	}
+	for _, old := range backups[min(len(backups), w.cfg.MaxBackups):] {
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to remove log backup %s: %v\n", old, err)
+		}
+	}
+}
+
+// backupName returns the name of the backup for a rotation at t:
+// "dir/app.log" becomes "dir/app-<timestamp>.log".
+func (w *FileWriter) backupName(t time.Time) string {
+	prefix, ext := w.nameParts()
+	return prefix + t.Format(backupTimeFormat) + ext
+}
+
+func (w *FileWriter) nameParts() (prefix, ext string) {
+	ext = filepath.Ext(w.cfg.Path)
+	return strings.TrimSuffix(w.cfg.Path, ext) + "-", ext
+}
+
+// backups returns the rotated files, newest first.
+func (w *FileWriter) backups() ([]string, error) {
+	prefix, ext := w.nameParts()
+	dir, base := filepath.Split(prefix)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		path string
+		t    time.Time
+	}
+	var found []backup
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), base)
+		if !ok || e.IsDir() {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ext))
+		if err != nil {
+			continue
+		}
+		found = append(found, backup{path: dir + e.Name(), t: t})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].t.After(found[j].t) })
+
+	paths := make([]string, len(found))
+	for i, b := range found {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
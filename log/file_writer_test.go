@@ -0,0 +1,190 @@
+package log
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readBackups(t *testing.T, w *FileWriter) []string {
+	t.Helper()
+	backups, err := w.backups()
+	require.NoError(t, err)
+	return backups
+}
+
+func TestFileWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rotates by size and keeps max backups", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "logs", "app.log")
+		w, err := NewFileWriter(FileConfig{Path: path, MaxBackups: 2})
+		require.NoError(t, err)
+		w.maxBytes = 10
+
+		for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			_, err := w.Write([]byte(line))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "fourth\n", string(data))
+
+		backups := readBackups(t, w)
+		require.Len(t, backups, 2)
+		newest, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, "third\n", string(newest))
+		assert.True(t, strings.HasPrefix(filepath.Base(backups[0]), "app-"))
+		assert.Equal(t, ".log", filepath.Ext(backups[0]))
+	})
+
+	t.Run("rotates by age", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "app.log")
+		w, err := NewFileWriter(FileConfig{Path: path, MaxAge: 20 * time.Millisecond})
+		require.NoError(t, err)
+		defer w.Close()
+
+		_, err = w.Write([]byte("old\n"))
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+		_, err = w.Write([]byte("new\n"))
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "new\n", string(data))
+		assert.Len(t, readBackups(t, w), 1)
+	})
+
+	t.Run("compresses backups", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "app.log")
+		w, err := NewFileWriter(FileConfig{Path: path, Compress: true})
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("rotated\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Rotate())
+		require.NoError(t, w.Close())
+
+		backups := readBackups(t, w)
+		require.Len(t, backups, 1)
+		require.True(t, strings.HasSuffix(backups[0], ".log.gz"))
+
+		f, err := os.Open(backups[0])
+		require.NoError(t, err)
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		data, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, "rotated\n", string(data))
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "app.log")
+		w, err := NewFileWriter(FileConfig{Path: path})
+		require.NoError(t, err)
+		w.maxBytes = 512
+
+		logger := New(WithWriter(w))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					logger.Info().Int("j", j).Msg("concurrent")
+				}
+			}()
+		}
+		wg.Wait()
+		require.NoError(t, w.Close())
+
+		lines := 0
+		for _, file := range append(readBackups(t, w), path) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				if line != "" {
+					assert.Contains(t, line, `"message":"concurrent"`)
+					lines++
+				}
+			}
+		}
+		assert.Equal(t, 400, lines)
+	})
+
+	t.Run("closed writer", func(t *testing.T) {
+		t.Parallel()
+		w, err := NewFileWriter(FileConfig{Path: filepath.Join(t.TempDir(), "app.log")})
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, w.Close())
+
+		_, err = w.Write([]byte("late\n"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewFileWriter(FileConfig{})
+		assert.EqualError(t, err, "log file path is empty")
+	})
+}
+
+func TestNewFromConfig_File(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger := NewFromConfig(Config{Level: "info", File: FileConfig{Path: path}})
+	logger.Info().Msg("to file")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"message":"to file"`)
+}
+
+func TestClose_File(t *testing.T) {
+	t.Parallel()
+
+	for name, async := range map[string]bool{"sync": false, "async": true} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "app.log")
+			logger := NewFromConfig(Config{
+				Level: "info",
+				File:  FileConfig{Path: path},
+				Async: AsyncConfig{Enabled: async},
+			})
+			logger.Info().Msg("before close")
+
+			adapter, ok := logger.(*zerologAdapter)
+			require.True(t, ok)
+			require.Len(t, adapter.cfg.files, 1)
+
+			require.NoError(t, Close(context.Background(), logger))
+			_, err := adapter.cfg.files[0].Write([]byte("after close\n"))
+			assert.ErrorIs(t, err, os.ErrClosed)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"message":"before close"`)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriter_ReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewFileWriter(FileConfig{Path: path})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	// Simulate an external logrotate: move the file away and signal the process.
+	require.NoError(t, os.Rename(path, filepath.Join(dir, "app.log.1")))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(data))
+}
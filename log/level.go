@@ -0,0 +1,96 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// LevelVar is a log level that can be changed at runtime.
+// All loggers created with the same LevelVar, and every logger derived from them
+// with With or WithOptions, observe its current value. It is safe for concurrent use.
+//
+// LevelVar implements http.Handler to read and change the level remotely:
+//
+//	GET  /log/level                  -> {"level":"info"}
+//	PUT  /log/level {"level":"debug"} -> {"level":"debug"}
+type LevelVar struct {
+	v atomic.Int32
+}
+
+// NewLevelVar creates a LevelVar set to level.
+func NewLevelVar(level Level) *LevelVar {
+	lv := &LevelVar{}
+	lv.Set(level)
+	return lv
+}
+
+// Level returns the current level.
+func (lv *LevelVar) Level() Level {
+	return Level(lv.v.Load())
+}
+
+// Set changes the level of every logger observing lv.
+func (lv *LevelVar) Set(level Level) {
+	lv.v.Store(int32(level))
+}
+
+// Enabled reports whether events of the given level are logged.
+func (lv *LevelVar) Enabled(level Level) bool {
+	current := lv.Level()
+	return current != LevelDisabled && level >= current
+}
+
+// String returns the name of the current level.
+func (lv *LevelVar) String() string {
+	return lv.Level().String()
+}
+
+type levelResponse struct {
+	Level string `json:"level"`
+}
+
+type levelError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP returns the current level on GET and changes it on PUT.
+// The PUT body is either JSON ({"level":"debug"}) or the plain level name.
+func (lv *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1024))
+		if err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, levelError{Error: err.Error()})
+			return
+		}
+
+		name := strings.TrimSpace(string(body))
+		var req levelResponse
+		if json.Unmarshal(body, &req) == nil {
+			name = req.Level
+		}
+
+		level, err := ParseLevel(name)
+		if err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, levelError{Error: err.Error()})
+			return
+		}
+		lv.Set(level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelJSON(w, http.StatusMethodNotAllowed, levelError{Error: "method not allowed"})
+		return
+	}
+
+	writeLevelJSON(w, http.StatusOK, levelResponse{Level: lv.String()})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelVar(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shared by derived loggers", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		lv := NewLevelVar(LevelInfo)
+		root := New(WithWriter(&buf), WithLevelVar(lv))
+		child := root.With(Str("component", "db"))
+		derived := root.WithOptions(WithService("worker"))
+		std := ToSlog(root)
+
+		child.Debug().Msg("hidden child")
+		derived.Debug().Msg("hidden derived")
+		std.Debug("hidden slog")
+		assert.Empty(t, buf.String())
+
+		lv.Set(LevelDebug)
+		child.Debug().Msg("visible child")
+		derived.Debug().Msg("visible derived")
+		std.Debug("visible slog")
+		assert.True(t, std.Enabled(context.Background(), slog.LevelDebug))
+
+		output := buf.String()
+		assert.Contains(t, output, "visible child")
+		assert.Contains(t, output, "visible derived")
+		assert.Contains(t, output, "visible slog")
+
+		lv.Set(LevelDisabled)
+		buf.Reset()
+		root.Error().Msg("disabled")
+		root.WithLevel(LevelError).Msg("disabled")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("default level var follows WithLevel", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithLevel(LevelWarn))
+
+		logger.Info().Msg("hidden")
+		logger.Warn().Msg("visible")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "visible")
+	})
+
+	t.Run("WithLevel detaches a derived logger", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		lv := NewLevelVar(LevelError)
+		root := New(WithWriter(&buf), WithLevelVar(lv))
+		verbose := root.WithOptions(WithLevel(LevelDebug))
+
+		verbose.Debug().Msg("detached")
+		root.Info().Msg("hidden")
+		assert.Contains(t, buf.String(), "detached")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Equal(t, LevelError, lv.Level())
+	})
+}
+
+func TestLevelVar_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	lv := NewLevelVar(LevelInfo)
+	server := httptest.NewServer(lv)
+	t.Cleanup(server.Close)
+
+	do := func(t *testing.T, method, body string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, strings.TrimSpace(buf.String())
+	}
+
+	status, body := do(t, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"level":"info"}`, body)
+
+	status, body = do(t, http.MethodPut, `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"level":"debug"}`, body)
+	assert.Equal(t, LevelDebug, lv.Level())
+
+	status, body = do(t, http.MethodPut, "warn\n")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"level":"warn"}`, body)
+
+	status, body = do(t, http.MethodPut, "loud")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "unknown log level")
+	assert.Equal(t, LevelWarn, lv.Level())
+
+	status, _ = do(t, http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
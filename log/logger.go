@@ -41,6 +41,9 @@ type Logger interface {
 	With(fields ...Field) Logger
 	WithLevel(level Level) Event
 	WithOptions(opts ...option) Logger
+	// Component returns a logger with the logkeys.Component field set to name
+	// and the level configured for it with WithComponentLevels, if any.
+	Component(name string) Logger
 }
 
 // New creates a logger with the given options.
@@ -129,6 +132,11 @@ func Bytes(key string, value []byte) Field      { return Field{key, value} }
 func Hex(key string, value []byte) Field        { return Field{key, value} } // Note: With() uses reflection, might log as b64 unless adapter is smart
 func RawJSON(key string, value []byte) Field    { return Field{key, value} }
 
+// String returns the name of the level (e.g. "info").
+func (l Level) String() string {
+	return levelToString(l)
+}
+
 // UnmarshalText implements encoding.TextUnmarshaler (for JSON/YAML decoding).
 // It returns an error if the level string is invalid.
 func (l *Level) UnmarshalText(text []byte) error {
@@ -11,7 +11,11 @@ import (
 
 type config struct {
 	level        Level
+	levelVar     *LevelVar
+	components   map[string]Level
+	sampling     map[Level]SamplingConfig
 	writers      []io.Writer
+	files        []*FileWriter // Opened by WithFileWriter, closed by Close
 	app          string
 	service      string
 	enableCaller bool
@@ -21,6 +25,7 @@ func (c *config) clone() *config {
 	newCfg := *c
 	newCfg.writers = make([]io.Writer, len(c.writers))
 	copy(newCfg.writers, c.writers)
+	newCfg.files = append([]*FileWriter(nil), c.files...)
 	return &newCfg
 }
 
@@ -28,9 +33,20 @@ func (c *config) clone() *config {
 type option func(*config)
 
 // WithLevel sets the logging level (debug, info, warn, error).
+// On a derived logger (WithOptions), it detaches the logger from the LevelVar of its parent.
 func WithLevel(level Level) option {
 	return func(c *config) {
 		c.level = level
+		c.levelVar = nil
+	}
+}
+
+// WithLevelVar makes the logger, and every logger derived from it, use the
+// level held by lv, which can be changed at runtime. It takes precedence over
+// WithLevel and the level of WithConfig if given after them.
+func WithLevelVar(lv *LevelVar) option {
+	return func(c *config) {
+		c.levelVar = lv
 	}
 }
 
@@ -100,6 +116,21 @@ func WithConfig(cfg Config) option {
 				panic(fmt.Sprintf("log: failed to configure logger: %v", err))
 			}
 			c.level = lvl
+			c.levelVar = nil
+		}
+		if len(cfg.Components) > 0 {
+			levels := make(map[string]Level, len(cfg.Components))
+			for _, component := range cfg.Components {
+				lvl, err := ParseLevel(component.Level)
+				if err != nil {
+					panic(fmt.Sprintf("log: failed to configure level of component %q: %v", component.Name, err))
+				}
+				levels[component.Name] = lvl
+			}
+			WithComponentLevels(levels)(c)
+		}
+		if len(cfg.Sampling) > 0 {
+			applySamplingConfig(c, cfg.Sampling)
 		}
 		if cfg.EnableCaller {
 			c.enableCaller = true
@@ -119,8 +150,14 @@ func WithConfig(cfg Config) option {
 			WithStdoutWriter()(c)
 		}
 
+		if cfg.File.Path != "" {
+			WithFileWriter(cfg.File)(c)
+		}
+
 		if len(c.writers) == 0 {
 			c.writers = append(c.writers, io.Discard)
+		} else if cfg.Async.Enabled {
+			WithAsync(cfg.Async)(c)
 		}
 	}
 }
@@ -143,3 +180,18 @@ func WithUDPWriter(addr string) option {
 		c.writers = append(c.writers, conn)
 	}
 }
+
+// WithFileWriter adds a writer that appends JSON logs to a file, rotating it
+// by size and/or age as configured (see FileConfig and NewFileWriter).
+func WithFileWriter(cfg FileConfig) option {
+	return func(c *config) {
+		w, err := NewFileWriter(cfg)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to open log file %s: %v\n", cfg.Path, err)
+			return
+		}
+
+		c.writers = append(c.writers, w)
+		c.files = append(c.files, w)
+	}
+}
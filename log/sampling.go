@@ -0,0 +1,222 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/shanth1/gotools/logkeys"
+)
+
+// Fields of the "suppressed N similar messages" event.
+const (
+	messageKeyField = "message_key"
+	suppressedField = "suppressed"
+)
+
+// SamplingConfig limits the number of events logged at a level.
+// Every and Burst can be combined: up to Burst events per Period are logged,
+// then one of every Every events until the period ends.
+type SamplingConfig struct {
+	// Every logs one of every N events. 0 and 1 log all events.
+	Every uint32 `mapstructure:"every" yaml:"every" json:"every" toml:"every"`
+
+	// Burst is the number of events logged per Period before Every applies
+	// (or, if Every is not set, before events are dropped).
+	Burst uint32 `mapstructure:"burst" yaml:"burst" json:"burst" toml:"burst"`
+
+	// Period is the window of Burst (e.g. "1s").
+	Period time.Duration `mapstructure:"period" yaml:"period" json:"period" toml:"period"`
+
+	// Dedup drops repeated events with the same message within this window.
+	// The message key is the message of Msg or the format of Msgf. When the window ends,
+	// a "suppressed N similar messages" event reports the dropped repetitions.
+	Dedup time.Duration `mapstructure:"dedup" yaml:"dedup" json:"dedup" toml:"dedup"`
+}
+
+// WithSampling applies sampling to the given levels, or to trace through error
+// if no level is given. Fatal and panic events are never sampled: passing
+// LevelFatal or LevelPanic PANICS, as dropping them would skip the exit or panic.
+func WithSampling(s SamplingConfig, levels ...Level) option {
+	return func(c *config) {
+		if len(levels) == 0 {
+			levels = []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError}
+		}
+		for _, level := range levels {
+			if level == LevelFatal || level == LevelPanic {
+				panic(fmt.Sprintf("log: failed to configure sampling: %s events cannot be sampled", level))
+			}
+		}
+		sampling := make(map[Level]SamplingConfig, len(c.sampling)+len(levels))
+		for level, cfg := range c.sampling {
+			sampling[level] = cfg
+		}
+		for _, level := range levels {
+			sampling[level] = s
+		}
+		c.sampling = sampling
+	}
+}
+
+// applySamplingConfig applies the Sampling section of Config.
+// It PANICS if a key is neither "*" nor a valid level, or is fatal or panic.
+func applySamplingConfig(c *config, sampling map[string]SamplingConfig) {
+	if s, ok := sampling["*"]; ok {
+		WithSampling(s)(c)
+	}
+	for name, s := range sampling {
+		if name == "*" {
+			continue
+		}
+		lvl, err := ParseLevel(name)
+		if err != nil {
+			panic(fmt.Sprintf("log: failed to configure sampling: %v", err))
+		}
+		WithSampling(s, lvl)(c)
+	}
+}
+
+// newSampler builds the zerolog sampler for the Every and Burst settings of each level.
+func newSampler(sampling map[Level]SamplingConfig) (zerolog.Sampler, bool) {
+	var ls zerolog.LevelSampler
+	found := false
+	for level, s := range sampling {
+		sampler := levelSampler(s)
+		if sampler == nil {
+			continue
+		}
+		found = true
+		switch level {
+		case LevelTrace:
+			ls.TraceSampler = sampler
+		case LevelDebug:
+			ls.DebugSampler = sampler
+		case LevelInfo:
+			ls.InfoSampler = sampler
+		case LevelWarn:
+			ls.WarnSampler = sampler
+		case LevelError:
+			ls.ErrorSampler = sampler
+		}
+	}
+	return ls, found
+}
+
+func levelSampler(s SamplingConfig) zerolog.Sampler {
+	var every zerolog.Sampler
+	if s.Every > 1 {
+		every = &zerolog.BasicSampler{N: s.Every}
+	}
+	if s.Burst > 0 && s.Period > 0 {
+		return &zerolog.BurstSampler{Burst: s.Burst, Period: s.Period, NextSampler: every}
+	}
+	return every
+}
+
+// newDedupers creates the deduplication state of the levels that enable it.
+func newDedupers(sampling map[Level]SamplingConfig) map[Level]*deduper {
+	var dedupers map[Level]*deduper
+	for level, s := range sampling {
+		if s.Dedup <= 0 {
+			continue
+		}
+		if dedupers == nil {
+			dedupers = map[Level]*deduper{}
+		}
+		dedupers[level] = &deduper{window: s.Dedup, entries: map[string]*dedupEntry{}}
+	}
+	return dedupers
+}
+
+// deduper drops repeated messages within a time window.
+type deduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	closed  bool // Set by Close: messages are no longer tracked
+}
+
+type dedupEntry struct {
+	suppressed int
+	timer      *time.Timer
+	summary    func(suppressed int)
+}
+
+// allow reports whether an event with the given message key is logged.
+// The first event of a key opens a window; repetitions within it are counted,
+// and summary is called with their number when the window ends or on flush.
+func (d *deduper) allow(key string, summary func(suppressed int)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return true
+	}
+	if entry, ok := d.entries[key]; ok {
+		entry.suppressed++
+		return false
+	}
+
+	entry := &dedupEntry{summary: summary}
+	d.entries[key] = entry
+	entry.timer = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		if d.entries[key] != entry {
+			// Already reported by flush.
+			d.mu.Unlock()
+			return
+		}
+		delete(d.entries, key)
+		suppressed := entry.suppressed
+		d.mu.Unlock()
+
+		if suppressed > 0 {
+			entry.summary(suppressed)
+		}
+	})
+	return true
+}
+
+// flush ends every open window, reporting the messages suppressed so far.
+// After a flush with close set, messages are no longer deduplicated.
+func (d *deduper) flush(close bool) {
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = map[string]*dedupEntry{}
+	d.closed = d.closed || close
+	d.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.timer.Stop()
+		if entry.suppressed > 0 {
+			entry.summary(entry.suppressed)
+		}
+	}
+}
+
+// flushDedupers reports the messages suppressed by the dedupers of the logger.
+func flushDedupers(logger Logger, close bool) {
+	adapter, ok := logger.(*zerologAdapter)
+	if !ok {
+		return
+	}
+	for _, d := range adapter.dedup {
+		d.flush(close)
+	}
+}
+
+// dedupSummary logs the number of messages suppressed for a key with the
+// logger of the first message. The summary itself is never sampled.
+func dedupSummary(l *zerologAdapter, level Level, key string) func(int) {
+	return func(suppressed int) {
+		logger := l.logger.Sample(nil)
+		event := logger.WithLevel(mapToZerologLevel(level))
+		if l.component != "" {
+			event.Str(logkeys.Component, l.component)
+		}
+		event.Str(messageKeyField, key).
+			Int(suppressedField, suppressed).
+			Msg(fmt.Sprintf("suppressed %d similar messages", suppressed))
+	}
+}
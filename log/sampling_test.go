@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the writes of dedup summaries from timers.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	t.Parallel()
+
+	t.Run("every nth", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Every: 3}, LevelInfo))
+
+		for i := 0; i < 9; i++ {
+			logger.Info().Msg("info event")
+			logger.Warn().Msg("warn event")
+		}
+
+		assert.Equal(t, 3, strings.Count(buf.String(), "info event"))
+		assert.Equal(t, 9, strings.Count(buf.String(), "warn event"))
+	})
+
+	t.Run("burst per period", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Burst: 2, Period: time.Hour}))
+
+		for i := 0; i < 5; i++ {
+			logger.Error().Msg("burst")
+		}
+
+		assert.Equal(t, 2, strings.Count(buf.String(), "burst"))
+	})
+
+	t.Run("burst then every nth", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Burst: 2, Period: time.Hour, Every: 4}))
+
+		for i := 0; i < 10; i++ {
+			logger.Info().Msg("event")
+		}
+
+		// 2 in the burst, then the 1st and 5th of the remaining 8.
+		assert.Equal(t, 4, strings.Count(buf.String(), "event"))
+	})
+
+	t.Run("dedup summarizes suppressed messages", func(t *testing.T) {
+		t.Parallel()
+		var buf lockedBuffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Dedup: 50 * time.Millisecond}, LevelWarn))
+
+		for i := 0; i < 4; i++ {
+			logger.Warn().Msgf("retry %d failed", i)
+		}
+		logger.Warn().Msg("other")
+		logger.Component("db").Info().Msg("info is not deduplicated")
+		logger.Component("db").Info().Msg("info is not deduplicated")
+
+		output := buf.String()
+		assert.Equal(t, 1, strings.Count(output, "failed"))
+		assert.Contains(t, output, "retry 0 failed")
+		assert.Contains(t, output, "other")
+		assert.Equal(t, 2, strings.Count(output, "info is not deduplicated"))
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(buf.String(), "suppressed 3 similar messages")
+		}, time.Second, 10*time.Millisecond)
+		output = buf.String()
+		assert.Contains(t, output, `"message_key":"retry %d failed"`)
+		assert.Contains(t, output, `"suppressed":3`)
+		assert.Equal(t, 1, strings.Count(output, "similar messages"), "no summary without repetitions")
+
+		logger.Warn().Msgf("retry %d failed", 5)
+		assert.Contains(t, buf.String(), "retry 5 failed", "a new window starts after the summary")
+	})
+
+	t.Run("derived loggers share the dedup state", func(t *testing.T) {
+		t.Parallel()
+		var buf lockedBuffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Dedup: time.Hour}))
+
+		logger.Info().Msg("connected")
+		logger.With(Field{Key: "id", Value: 1}).Info().Msg("connected")
+		logger.Component("db").Info().Msg("connected")
+
+		assert.Equal(t, 1, strings.Count(buf.String(), "connected"))
+	})
+
+	t.Run("flush and close report pending summaries", func(t *testing.T) {
+		t.Parallel()
+		var buf lockedBuffer
+		logger := New(WithWriter(&buf), WithSampling(SamplingConfig{Dedup: time.Hour}, LevelWarn))
+		db := logger.Component("db")
+
+		for i := 0; i < 3; i++ {
+			db.Warn().Msg("slow query")
+		}
+		require.NoError(t, Flush(context.Background(), logger))
+		assert.Contains(t, buf.String(), "suppressed 2 similar messages")
+		assert.Contains(t, buf.String(), `"component":"db"`)
+
+		db.Warn().Msg("slow query")
+		db.Warn().Msg("slow query")
+		require.NoError(t, Close(context.Background(), logger))
+		assert.Contains(t, buf.String(), "suppressed 1 similar messages")
+		assert.Equal(t, 2, strings.Count(buf.String(), `"message":"slow query"`), "flush starts a new window")
+		assert.Equal(t, 2, strings.Count(buf.String(), "similar messages"))
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := NewFromConfig(Config{
+			Level: "debug",
+			Sampling: map[string]SamplingConfig{
+				"*":     {Every: 2},
+				"error": {},
+			},
+		}).WithOptions(WithWriter(&buf))
+
+		for i := 0; i < 4; i++ {
+			logger.Debug().Msg("debug event")
+			logger.Error().Msg("error event")
+		}
+
+		assert.Equal(t, 2, strings.Count(buf.String(), "debug event"))
+		assert.Equal(t, 4, strings.Count(buf.String(), "error event"), "a level entry replaces \"*\"")
+	})
+
+	t.Run("fatal and panic cannot be sampled", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicsWithValue(t, "log: failed to configure sampling: panic events cannot be sampled", func() {
+			New(WithWriter(&lockedBuffer{}), WithSampling(SamplingConfig{Dedup: time.Minute}, LevelPanic))
+		})
+		assert.Panics(t, func() {
+			NewFromConfig(Config{Sampling: map[string]SamplingConfig{"fatal": {Dedup: time.Minute}}})
+		})
+
+		// "*" covers trace through error only, so repeated panics still panic.
+		var buf lockedBuffer
+		logger := NewFromConfig(Config{Sampling: map[string]SamplingConfig{"*": {Dedup: time.Minute}}}).
+			WithOptions(WithWriter(&buf))
+		assert.Panics(t, func() { logger.Panic().Msg("boom") })
+		assert.Panics(t, func() { logger.Panic().Msg("boom") })
+	})
+
+	t.Run("invalid config level panics", func(t *testing.T) {
+		t.Parallel()
+		assert.Panics(t, func() {
+			NewFromConfig(Config{Sampling: map[string]SamplingConfig{"loud": {Every: 2}}})
+		})
+	})
+}